@@ -15,6 +15,7 @@ import (
 
 	apiHandlers "github.com/dcotelo/chartimpact/backend/internal/api/handlers"
 	"github.com/dcotelo/chartimpact/backend/internal/api/middleware"
+	"github.com/dcotelo/chartimpact/backend/internal/diff"
 	"github.com/dcotelo/chartimpact/backend/internal/service"
 	"github.com/dcotelo/chartimpact/backend/internal/storage"
 	"github.com/dcotelo/chartimpact/backend/internal/util"
@@ -108,6 +109,20 @@ func main() {
 		log.Info("Storage is disabled (set STORAGE_ENABLED=true to enable)")
 	}
 
+	// Key the Secret redaction fingerprints, so that stored results stay comparable across restarts
+	if secretHashKey := os.Getenv("SECRET_HASH_KEY"); secretHashKey != "" {
+		if err := diff.SetSecretHashKey(secretHashKey); err != nil {
+			log.Fatalf("Invalid SECRET_HASH_KEY: %v", err)
+		}
+	} else {
+		if err := diff.GenerateSecretHashKey(); err != nil {
+			log.Fatalf("Failed to initialize secret redaction: %v", err)
+		}
+		if storageEnabled {
+			log.Warn("SECRET_HASH_KEY not set, redacted Secret values in stored results will not match after a restart")
+		}
+	}
+
 	// Initialize services
 	helmService := service.NewHelmService()
 
@@ -122,6 +137,8 @@ func main() {
 	// API routes
 	api := router.PathPrefix("/api").Subrouter()
 	api.HandleFunc("/compare", apiHandlers.CompareHandler(helmService, store)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/compare/values", apiHandlers.CompareValuesHandler(helmService, store)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/diff/manifests", apiHandlers.DiffManifestsHandler(helmService)).Methods("POST", "OPTIONS")
 	api.HandleFunc("/versions", apiHandlers.VersionsHandler()).Methods("POST", "OPTIONS")
 	api.HandleFunc("/health", apiHandlers.HealthHandler(store)).Methods("GET")
 
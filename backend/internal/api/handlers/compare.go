@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/dcotelo/chartimpact/backend/internal/diff"
 	"github.com/dcotelo/chartimpact/backend/internal/models"
 	"github.com/dcotelo/chartimpact/backend/internal/service"
 	"github.com/dcotelo/chartimpact/backend/internal/storage"
@@ -33,91 +35,151 @@ func CompareHandler(helmService *service.HelmService, store storage.ComparisonSt
 			return
 		}
 
-		// Validate required fields
-		if req.Repository == "" {
+		// Validate the effective source of each side; per-side descriptors
+		// may replace the shared repository, chart path and source type
+		left, right := service.ResolveSources(&req)
+		if msg := validateSource(left, "Version 1"); msg != "" {
 			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
 				Success: false,
-				Error:   "Repository URL is required",
+				Error:   msg,
+			})
+			return
+		}
+		if msg := validateSource(right, "Version 2"); msg != "" {
+			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
+				Success: false,
+				Error:   msg,
 			})
 			return
 		}
 
-		if req.ChartPath == "" {
+		if msg := validateCompareOptions(&req); msg != "" {
 			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
 				Success: false,
-				Error:   "Chart path is required",
+				Error:   msg,
 			})
 			return
 		}
 
-		if req.Version1 == "" {
+		// Get timeout from environment or use default
+		timeout := getTimeoutFromEnv("COMPARE_TIMEOUT", 120)
+		ctx, cancel := context.WithTimeout(r.Context(), time.Duration(timeout)*time.Second)
+		defer cancel()
+
+		log.WithFields(log.Fields{
+			"repository1": left.Repository,
+			"chartPath1":  left.ChartPath,
+			"version1":    left.Version,
+			"repository2": right.Repository,
+			"chartPath2":  right.ChartPath,
+			"version2":    right.Version,
+		}).Info("Received compare request")
+
+		// Check storage for existing result (if enabled)
+		if cached := lookupStoredComparison(ctx, store, &req); cached != nil {
+			respondJSON(w, http.StatusOK, cached)
+			return
+		}
+
+		// Call Helm service to compare versions
+		response, err := helmService.CompareVersions(ctx, &req)
+		if err != nil {
+			log.Errorf("Failed to compare versions: %v", err)
+			respondJSON(w, http.StatusInternalServerError, models.CompareResponse{
+				Success: false,
+				Error:   "Internal server error: " + err.Error(),
+			})
+			return
+		}
+
+		// Store result if storage is enabled and comparison was successful
+		if store != nil && response.Success && response.StructuredDiff != nil {
+			go saveComparison(store, req, response)
+		}
+
+		// Return response
+		if response.Success {
+			respondJSON(w, http.StatusOK, response)
+		} else {
+			respondJSON(w, http.StatusBadRequest, response)
+		}
+	}
+}
+
+// CompareValuesHandler handles POST /api/compare/values requests
+// Renders a single chart version with two values sets and returns the diff
+func CompareValuesHandler(helmService *service.HelmService, store storage.ComparisonStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.CompareRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Errorf("Failed to decode request body: %v", err)
+			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
+				Success: false,
+				Error:   "Invalid request body: " + err.Error(),
+			})
+			return
+		}
+
+		if req.Version == "" {
 			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
 				Success: false,
-				Error:   "Version 1 is required",
+				Error:   "Version is required",
 			})
 			return
 		}
 
-		if req.Version2 == "" {
+		source, _ := service.ResolveSources(&req)
+		if msg := validateSource(source, "Version"); msg != "" {
 			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
 				Success: false,
-				Error:   "Version 2 is required",
+				Error:   msg,
 			})
 			return
 		}
 
-		// Validate repository URL format
-		if !strings.HasPrefix(req.Repository, "https://") &&
-			!strings.HasPrefix(req.Repository, "http://") &&
-			!strings.HasPrefix(req.Repository, "git@") {
+		// Both sides render the shared source, so per-side sources would be ignored
+		if req.Left != nil || req.Right != nil {
 			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
 				Success: false,
-				Error:   "Invalid repository URL format. Must start with https://, http://, or git@",
+				Error:   "left and right sources are not supported for values comparisons",
+			})
+			return
+		}
+
+		if req.LeftValues == nil && req.RightValues == nil {
+			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
+				Success: false,
+				Error:   "At least one of leftValues or rightValues is required",
+			})
+			return
+		}
+
+		if msg := validateCompareOptions(&req); msg != "" {
+			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
+				Success: false,
+				Error:   msg,
 			})
 			return
 		}
 
-		// Get timeout from environment or use default
 		timeout := getTimeoutFromEnv("COMPARE_TIMEOUT", 120)
 		ctx, cancel := context.WithTimeout(r.Context(), time.Duration(timeout)*time.Second)
 		defer cancel()
 
 		log.WithFields(log.Fields{
-			"repository": req.Repository,
-			"chartPath":  req.ChartPath,
-			"version1":   req.Version1,
-			"version2":   req.Version2,
-		}).Info("Received compare request")
+			"repository": source.Repository,
+			"chartPath":  source.ChartPath,
+			"version":    source.Version,
+		}).Info("Received values compare request")
 
-		// Check storage for existing result (if enabled)
-		if store != nil {
-			contentHash := storage.ComputeContentHash(&req)
-			log.Debugf("Content hash: %s", contentHash)
-
-			// Try to find existing comparison
-			existing, err := store.GetByHash(ctx, contentHash)
-			if err == nil && existing != nil {
-				log.Infof("Cache hit for hash %s, returning stored result %s", contentHash[:8], existing.CompareID)
-
-				// Return cached result
-				response := models.CompareResponse{
-					Success:                 true,
-					Diff:                    "", // Legacy, can be empty
-					StructuredDiff:          existing.StructuredDiff,
-					StructuredDiffAvailable: true,
-					Version1:                existing.Version1,
-					Version2:                existing.Version2,
-				}
-
-				respondJSON(w, http.StatusOK, response)
-				return
-			}
+		if cached := lookupStoredComparison(ctx, store, &req); cached != nil {
+			respondJSON(w, http.StatusOK, cached)
+			return
 		}
 
-		// Call Helm service to compare versions
-		response, err := helmService.CompareVersions(ctx, &req)
+		response, err := helmService.CompareValues(ctx, &req)
 		if err != nil {
-			log.Errorf("Failed to compare versions: %v", err)
+			log.Errorf("Failed to compare values: %v", err)
 			respondJSON(w, http.StatusInternalServerError, models.CompareResponse{
 				Success: false,
 				Error:   "Internal server error: " + err.Error(),
@@ -125,60 +187,10 @@ func CompareHandler(helmService *service.HelmService, store storage.ComparisonSt
 			return
 		}
 
-		// Store result if storage is enabled and comparison was successful
 		if store != nil && response.Success && response.StructuredDiff != nil {
-			go func() {
-				storeCtx, storeCancel := context.WithTimeout(context.Background(), 30*time.Second)
-				defer storeCancel()
-
-				retentionDays := util.GetIntEnv("RESULT_TTL_DAYS", 30)
-				contentHash := storage.ComputeContentHash(&req)
-
-				// Ensure compare_id exists in metadata
-				if response.StructuredDiff.Metadata.CompareID == "" {
-					response.StructuredDiff.Metadata.CompareID = uuid.New().String()
-				}
-
-				compareID, err := uuid.Parse(response.StructuredDiff.Metadata.CompareID)
-				if err != nil {
-					log.Errorf("Invalid compare_id format: %v", err)
-					return
-				}
-
-				valuesSHA256 := ""
-				if req.ValuesContent != nil && *req.ValuesContent != "" {
-					valuesSHA256 = storage.ComputeValuesSHA256(*req.ValuesContent)
-				}
-
-				saveReq := &storage.SaveComparisonRequest{
-					CompareID:      compareID,
-					ContentHash:    contentHash,
-					Repository:     req.Repository,
-					ChartPath:      req.ChartPath,
-					Version1:       req.Version1,
-					Version2:       req.Version2,
-					ValuesFile:     req.ValuesFile,
-					ValuesSHA256:   stringPtrIfNotEmpty(valuesSHA256),
-					StructuredDiff: response.StructuredDiff,
-					EngineVersion:  response.StructuredDiff.Metadata.EngineVersion,
-					HelmVersion:    "", // Could extract from metadata if available
-					RetentionDays:  retentionDays,
-				}
-
-				stored, err := store.Save(storeCtx, saveReq)
-				if err != nil {
-					log.Errorf("Failed to store comparison result: %v", err)
-				} else {
-					if stored.Deduplicated {
-						log.Infof("Stored comparison result (deduplicated): %s", stored.CompareID)
-					} else {
-						log.Infof("Stored comparison result: %s (expires: %s)", stored.CompareID, stored.ExpiresAt.Format(time.RFC3339))
-					}
-				}
-			}()
+			go saveComparison(store, req, response)
 		}
 
-		// Return response
 		if response.Success {
 			respondJSON(w, http.StatusOK, response)
 		} else {
@@ -187,6 +199,155 @@ func CompareHandler(helmService *service.HelmService, store storage.ComparisonSt
 	}
 }
 
+// validateSource checks that one side's source is complete and well-formed.
+// Returns an error message, or "" if the source is valid.
+func validateSource(source models.SourceDescriptor, versionLabel string) string {
+	if source.Repository == "" {
+		return "Repository URL is required"
+	}
+	if source.ChartPath == "" {
+		return "Chart path is required"
+	}
+	if source.Version == "" {
+		return versionLabel + " is required"
+	}
+
+	// Validate repository format for the chart source
+	switch source.SourceType {
+	case service.SourceTypeGit, service.SourceTypeKustomize:
+		if !strings.HasPrefix(source.Repository, "https://") &&
+			!strings.HasPrefix(source.Repository, "http://") &&
+			!strings.HasPrefix(source.Repository, "git@") {
+			return "Invalid repository URL format. Must start with https://, http://, or git@"
+		}
+	case service.SourceTypeHelmRepo:
+		if !strings.HasPrefix(source.Repository, "https://") && !strings.HasPrefix(source.Repository, "http://") {
+			return "Invalid Helm repository URL format. Must start with https:// or http://"
+		}
+	case service.SourceTypeOCI:
+		if !strings.HasPrefix(source.Repository, "oci://") {
+			return "Invalid OCI repository format. Must start with oci://"
+		}
+	case service.SourceTypeTarball:
+		// Local paths are validated against LOCAL_CHARTS_DIR by the service
+	default:
+		return "Invalid sourceType. Must be one of: git, helm-repo, oci, tarball, kustomize"
+	}
+
+	return ""
+}
+
+// validateCompareOptions checks the diff options shared by all comparison endpoints.
+// Returns an error message, or "" if the options are valid.
+func validateCompareOptions(req *models.CompareRequest) string {
+	// Validate suppression regex up front so a typo is reported as a bad request
+	if req.SuppressRegex != nil && *req.SuppressRegex != "" {
+		if _, err := regexp.Compile(*req.SuppressRegex); err != nil {
+			return "Invalid suppressRegex: " + err.Error()
+		}
+	}
+
+	if !diff.IsValidSecretHandling(req.SecretHandling) {
+		return "Invalid secretHandling. Must be one of: suppress, show, decode"
+	}
+
+	if req.ContextLines != nil && (*req.ContextLines < 0 || *req.ContextLines > diff.MaxContextLines) {
+		return "Invalid contextLines. Must be between 0 and " + strconv.Itoa(diff.MaxContextLines)
+	}
+
+	return ""
+}
+
+// lookupStoredComparison returns a stored result for an identical request, or nil
+func lookupStoredComparison(ctx context.Context, store storage.ComparisonStore, req *models.CompareRequest) *models.CompareResponse {
+	// Stored results always have secrets redacted, so they can only answer
+	// requests that would have redacted them too
+	exposesSecrets := req.SecretHandling == diff.SecretHandlingShow || req.SecretHandling == diff.SecretHandlingDecode
+	if store == nil || exposesSecrets {
+		return nil
+	}
+
+	contentHash := storage.ComputeContentHash(req)
+	log.Debugf("Content hash: %s", contentHash)
+
+	// Try to find existing comparison
+	existing, err := store.GetByHash(ctx, contentHash)
+	if err != nil || existing == nil {
+		return nil
+	}
+	log.Infof("Cache hit for hash %s, returning stored result %s", contentHash[:8], existing.CompareID)
+
+	var warnings []models.ValuesWarning
+	if existing.StructuredDiff != nil {
+		warnings = existing.StructuredDiff.Warnings
+	}
+
+	return &models.CompareResponse{
+		Success:                 true,
+		Diff:                    "", // Legacy, can be empty
+		StructuredDiff:          existing.StructuredDiff,
+		StructuredDiffAvailable: true,
+		Version1:                existing.Version1,
+		Version2:                existing.Version2,
+		Warnings:                warnings,
+	}
+}
+
+// saveComparison stores a successful comparison result with secrets redacted
+func saveComparison(store storage.ComparisonStore, req models.CompareRequest, response *models.CompareResponse) {
+	storeCtx, storeCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer storeCancel()
+
+	retentionDays := util.GetIntEnv("RESULT_TTL_DAYS", 30)
+	contentHash := storage.ComputeContentHash(&req)
+
+	// Ensure compare_id exists in metadata
+	if response.StructuredDiff.Metadata.CompareID == "" {
+		response.StructuredDiff.Metadata.CompareID = uuid.New().String()
+	}
+
+	compareID, err := uuid.Parse(response.StructuredDiff.Metadata.CompareID)
+	if err != nil {
+		log.Errorf("Invalid compare_id format: %v", err)
+		return
+	}
+
+	valuesSHA256 := ""
+	if req.ValuesContent != nil && *req.ValuesContent != "" {
+		valuesSHA256 = storage.ComputeValuesSHA256(*req.ValuesContent)
+	}
+
+	// Stored comparisons are listed by the left source; both sources
+	// are recorded in the structured diff metadata
+	left, right := service.ResolveSources(&req)
+
+	saveReq := &storage.SaveComparisonRequest{
+		CompareID:      compareID,
+		ContentHash:    contentHash,
+		Repository:     left.Repository,
+		ChartPath:      left.ChartPath,
+		Version1:       left.Version,
+		Version2:       right.Version,
+		ValuesFile:     req.ValuesFile,
+		ValuesSHA256:   stringPtrIfNotEmpty(valuesSHA256),
+		StructuredDiff: service.RedactSecrets(response.StructuredDiff),
+		EngineVersion:  response.StructuredDiff.Metadata.EngineVersion,
+		HelmVersion:    "", // Could extract from metadata if available
+		RetentionDays:  retentionDays,
+	}
+
+	stored, err := store.Save(storeCtx, saveReq)
+	if err != nil {
+		log.Errorf("Failed to store comparison result: %v", err)
+	} else {
+		if stored.Deduplicated {
+			log.Infof("Stored comparison result (deduplicated): %s", stored.CompareID)
+		} else {
+			log.Infof("Stored comparison result: %s (expires: %s)", stored.CompareID, stored.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+}
+
 // getTimeoutFromEnv retrieves a timeout value from environment or returns default
 func getTimeoutFromEnv(key string, defaultValue int) int {
 	if val := os.Getenv(key); val != "" {
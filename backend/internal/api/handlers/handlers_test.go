@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +15,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/dcotelo/chartimpact/backend/internal/models"
+	"github.com/dcotelo/chartimpact/backend/internal/service"
 	"github.com/dcotelo/chartimpact/backend/internal/storage"
 )
 
@@ -443,6 +447,415 @@ func TestHealthHandler_WithoutStorage(t *testing.T) {
 	}
 }
 
+func TestCompareHandler_InvalidSuppressRegex(t *testing.T) {
+	body := `{"repository":"https://github.com/test/repo.git","chartPath":"charts/app","version1":"1.0.0","version2":"1.1.0","suppressRegex":"checksum/("}`
+	req := httptest.NewRequest("POST", "/api/compare", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(CompareHandler(service.NewHelmService(), nil))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+
+	var response models.CompareResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if !strings.Contains(response.Error, "Invalid suppressRegex") {
+		t.Errorf("Expected suppressRegex error, got: %s", response.Error)
+	}
+}
+
+func TestCompareHandler_InvalidSecretHandling(t *testing.T) {
+	body := `{"repository":"https://github.com/test/repo.git","chartPath":"charts/app","version1":"1.0.0","version2":"1.1.0","secretHandling":"plain"}`
+	req := httptest.NewRequest("POST", "/api/compare", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(CompareHandler(service.NewHelmService(), nil))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestCompareHandler_InvalidContextLines(t *testing.T) {
+	body := `{"repository":"https://github.com/test/repo.git","chartPath":"charts/app","version1":"1.0.0","version2":"1.1.0","contextLines":9223372036854775807}`
+	req := httptest.NewRequest("POST", "/api/compare", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(CompareHandler(service.NewHelmService(), nil))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+
+	var response models.CompareResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !strings.Contains(response.Error, "Invalid contextLines") {
+		t.Errorf("Expected contextLines error, got: %s", response.Error)
+	}
+}
+
+func TestDiffManifestsHandler_MultipartHugeContextLines(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("manifest1", testManifest1)
+	writer.WriteField("manifest2", testManifest2)
+	writer.WriteField("contextLines", "9223372036854775807")
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/diff/manifests", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(DiffManifestsHandler(service.NewHelmService()))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestCompareHandler_SourceTypeValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "unknown source type",
+			body:     `{"repository":"https://charts.example.com","chartPath":"app","sourceType":"s3","version1":"1.0.0","version2":"1.1.0"}`,
+			expected: "Invalid sourceType",
+		},
+		{
+			name:     "helm repository without http",
+			body:     `{"repository":"charts.example.com","chartPath":"app","sourceType":"helm-repo","version1":"1.0.0","version2":"1.1.0"}`,
+			expected: "Invalid Helm repository URL format",
+		},
+		{
+			name:     "invalid per-side source",
+			body:     `{"repository":"https://github.com/test/repo.git","chartPath":"charts/app","version1":"main","version2":"main","left":{"repository":"https://ghcr.io/org/charts","chartPath":"app","sourceType":"oci","version":"4.2.0"}}`,
+			expected: "Must start with oci://",
+		},
+		{
+			name:     "per-side source missing version",
+			body:     `{"left":{"repository":"https://charts.example.com","chartPath":"app","sourceType":"helm-repo","version":"4.2.0"},"right":{"repository":"https://github.com/test/repo.git","chartPath":"charts/app"}}`,
+			expected: "Version 2 is required",
+		},
+		{
+			name:     "kustomize without git URL",
+			body:     `{"repository":"github.com/test/repo","chartPath":"overlays/prod","sourceType":"kustomize","version1":"v1","version2":"v2"}`,
+			expected: "Invalid repository URL format",
+		},
+		{
+			name:     "oci without scheme",
+			body:     `{"repository":"https://ghcr.io/org/charts","chartPath":"app","sourceType":"oci","version1":"1.0.0","version2":"1.1.0"}`,
+			expected: "Must start with oci://",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/compare", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(CompareHandler(service.NewHelmService(), nil))
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", rec.Code)
+			}
+
+			var response models.CompareResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !strings.Contains(response.Error, tt.expected) {
+				t.Errorf("Expected error containing %q, got: %s", tt.expected, response.Error)
+			}
+		})
+	}
+}
+
+func TestCompareValuesHandler_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "missing version",
+			body:     `{"repository":"https://github.com/test/repo.git","chartPath":"charts/app","rightValues":{"set":["replicas=3"]}}`,
+			expected: "Version is required",
+		},
+		{
+			name:     "missing values",
+			body:     `{"repository":"https://github.com/test/repo.git","chartPath":"charts/app","version":"1.0.0"}`,
+			expected: "leftValues or rightValues",
+		},
+		{
+			name:     "per-side source",
+			body:     `{"repository":"https://github.com/test/repo.git","chartPath":"charts/app","version":"1.0.0","rightValues":{"set":["replicas=3"]},"right":{"repository":"https://github.com/fork/repo.git"}}`,
+			expected: "left and right sources are not supported",
+		},
+		{
+			name:     "invalid secret handling",
+			body:     `{"repository":"https://github.com/test/repo.git","chartPath":"charts/app","version":"1.0.0","rightValues":{"set":["replicas=3"]},"secretHandling":"plain"}`,
+			expected: "Invalid secretHandling",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/compare/values", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(CompareValuesHandler(service.NewHelmService(), nil))
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", rec.Code)
+			}
+
+			var response models.CompareResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !strings.Contains(response.Error, tt.expected) {
+				t.Errorf("Expected error containing %q, got: %s", tt.expected, response.Error)
+			}
+		})
+	}
+}
+
+func TestCompareValuesHandler_CacheHit(t *testing.T) {
+	body := `{"repository":"https://github.com/test/repo.git","chartPath":"charts/app","version":"1.0.0","rightValues":{"set":["replicas=3"]}}`
+
+	var request models.CompareRequest
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		t.Fatalf("Failed to decode request: %v", err)
+	}
+	expectedHash := storage.ComputeContentHash(&request)
+
+	mockStore := &MockStorage{
+		GetByHashFunc: func(ctx context.Context, contentHash string) (*storage.StoredComparison, error) {
+			if contentHash != expectedHash {
+				return nil, nil
+			}
+			return &storage.StoredComparison{
+				CompareID: uuid.New(),
+				Version1:  "1.0.0",
+				Version2:  "1.0.0",
+				StructuredDiff: &models.StructuredDiffResult{
+					Metadata: models.DiffMetadata{ComparisonMode: "values"},
+				},
+			}, nil
+		},
+	}
+
+	req := httptest.NewRequest("POST", "/api/compare/values", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(CompareValuesHandler(service.NewHelmService(), mockStore))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response models.CompareResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.StructuredDiff == nil || response.StructuredDiff.Metadata.ComparisonMode != "values" {
+		t.Error("Expected stored values comparison to be returned")
+	}
+}
+
+const testManifest1 = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  replicas: "1"
+`
+
+const testManifest2 = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  replicas: "2"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`
+
+// assertManifestDiff checks a successful diff of testManifest1 against testManifest2
+func assertManifestDiff(t *testing.T, rec *httptest.ResponseRecorder, name1, name2 string) {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response models.CompareResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.StructuredDiff == nil {
+		t.Fatal("Expected structured diff")
+	}
+
+	inputs := response.StructuredDiff.Metadata.Inputs
+	if inputs.Left.Source != "manifests" || inputs.Right.Source != "manifests" {
+		t.Errorf("Expected source manifests, got %q and %q", inputs.Left.Source, inputs.Right.Source)
+	}
+	if inputs.Left.Version != name1 || inputs.Right.Version != name2 {
+		t.Errorf("Expected names %q and %q, got %q and %q", name1, name2, inputs.Left.Version, inputs.Right.Version)
+	}
+	if response.StructuredDiff.Stats.Resources.Added != 1 || response.StructuredDiff.Stats.Resources.Modified != 1 {
+		t.Errorf("Expected 1 added and 1 modified resource, got %+v", response.StructuredDiff.Stats.Resources)
+	}
+}
+
+func TestDiffManifestsHandler_JSON(t *testing.T) {
+	body, _ := json.Marshal(models.ManifestDiffRequest{
+		Manifest1: testManifest1,
+		Manifest2: testManifest2,
+		Name1:     "before",
+		Name2:     "after",
+	})
+	req := httptest.NewRequest("POST", "/api/diff/manifests", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(DiffManifestsHandler(service.NewHelmService()))
+	handler.ServeHTTP(rec, req)
+
+	assertManifestDiff(t, rec, "before", "after")
+}
+
+func TestDiffManifestsHandler_Multipart(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("manifest1", "prod.yaml")
+	part.Write([]byte(testManifest1))
+	part, _ = writer.CreateFormFile("manifest2", "staging.yaml")
+	part.Write([]byte(testManifest2))
+	writer.WriteField("suppressKinds", "Secret")
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/diff/manifests", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(DiffManifestsHandler(service.NewHelmService()))
+	handler.ServeHTTP(rec, req)
+
+	assertManifestDiff(t, rec, "prod.yaml", "staging.yaml")
+}
+
+func TestDiffManifestsHandler_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "missing manifest",
+			body:     `{"manifest1":"kind: ConfigMap"}`,
+			expected: "manifest1 and manifest2 are required",
+		},
+		{
+			name:     "invalid suppress regex",
+			body:     `{"manifest1":"kind: ConfigMap","manifest2":"kind: ConfigMap","suppressRegex":"["}`,
+			expected: "Invalid suppressRegex",
+		},
+		{
+			name:     "huge context lines",
+			body:     `{"manifest1":"kind: ConfigMap","manifest2":"kind: ConfigMap","contextLines":9223372036854775807}`,
+			expected: "Invalid contextLines",
+		},
+		{
+			name:     "negative context lines",
+			body:     `{"manifest1":"kind: ConfigMap","manifest2":"kind: ConfigMap","contextLines":-1}`,
+			expected: "Invalid contextLines",
+		},
+		{
+			name:     "invalid body",
+			body:     `not json`,
+			expected: "Invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/diff/manifests", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(DiffManifestsHandler(service.NewHelmService()))
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", rec.Code)
+			}
+
+			var response models.CompareResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !strings.Contains(response.Error, tt.expected) {
+				t.Errorf("Expected error containing %q, got: %s", tt.expected, response.Error)
+			}
+		})
+	}
+}
+
+func TestSaveComparison_RedactsValuesOverrides(t *testing.T) {
+	var saved *storage.SaveComparisonRequest
+	store := &MockStorage{
+		SaveFunc: func(ctx context.Context, req *storage.SaveComparisonRequest) (*storage.StoredComparison, error) {
+			saved = req
+			return &storage.StoredComparison{CompareID: req.CompareID}, nil
+		},
+	}
+	response := &models.CompareResponse{
+		Success: true,
+		StructuredDiff: &models.StructuredDiffResult{
+			Values: &models.ValuesDiff{
+				Masked: []models.DefaultValueChange{
+					{Path: "auth.password", Op: "replace", Before: "", After: "changeme", Override: "s3cr3t"},
+				},
+			},
+		},
+	}
+
+	saveComparison(store, models.CompareRequest{Repository: "https://github.com/test/repo.git", ChartPath: "charts/app", Version1: "1.0.0", Version2: "1.1.0"}, response)
+
+	if saved == nil {
+		t.Fatal("Expected the comparison to be saved")
+	}
+	stored, err := json.Marshal(saved.StructuredDiff)
+	if err != nil {
+		t.Fatalf("Failed to marshal stored result: %v", err)
+	}
+	if strings.Contains(string(stored), "s3cr3t") {
+		t.Errorf("Stored result contains a supplied value: %s", stored)
+	}
+	if response.StructuredDiff.Values.Masked[0].Override != "s3cr3t" {
+		t.Error("The response served to the client should keep the supplied value")
+	}
+}
+
 // Basic test placeholder - handlers are tested via integration tests
 func TestHandlersPackage(t *testing.T) {
 	t.Log("Handlers package compiles successfully")
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dcotelo/chartimpact/backend/internal/diff"
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+	"github.com/dcotelo/chartimpact/backend/internal/service"
+	"github.com/dcotelo/chartimpact/backend/internal/util"
+)
+
+// DiffManifestsHandler handles POST /api/diff/manifests requests
+// Diffs two raw multi-document YAML bundles sent as JSON or as a multipart upload
+func DiffManifestsHandler(helmService *service.HelmService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		maxBytes := util.GetInt64Env("MANIFEST_MAX_SIZE_MB", 10) * 1024 * 1024
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+		req, err := decodeManifestDiffRequest(r, maxBytes)
+		if err != nil {
+			log.Errorf("Failed to decode request body: %v", err)
+			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
+				Success: false,
+				Error:   "Invalid request body: " + err.Error(),
+			})
+			return
+		}
+
+		if strings.TrimSpace(req.Manifest1) == "" || strings.TrimSpace(req.Manifest2) == "" {
+			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
+				Success: false,
+				Error:   "manifest1 and manifest2 are required",
+			})
+			return
+		}
+
+		if msg := validateCompareOptions(&models.CompareRequest{
+			SecretHandling: req.SecretHandling,
+			SuppressRegex:  req.SuppressRegex,
+			ContextLines:   req.ContextLines,
+		}); msg != "" {
+			respondJSON(w, http.StatusBadRequest, models.CompareResponse{
+				Success: false,
+				Error:   msg,
+			})
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"name1": req.Name1,
+			"name2": req.Name2,
+			"size1": len(req.Manifest1),
+			"size2": len(req.Manifest2),
+		}).Info("Received manifest diff request")
+
+		response, err := helmService.CompareManifests(req)
+		if err != nil {
+			log.Errorf("Failed to compare manifests: %v", err)
+			respondJSON(w, http.StatusInternalServerError, models.CompareResponse{
+				Success: false,
+				Error:   "Internal server error: " + err.Error(),
+			})
+			return
+		}
+
+		if response.Success {
+			respondJSON(w, http.StatusOK, response)
+		} else {
+			respondJSON(w, http.StatusBadRequest, response)
+		}
+	}
+}
+
+// decodeManifestDiffRequest reads a manifest diff request from a JSON body or a
+// multipart form with manifest1/manifest2 file parts and the options as form fields
+func decodeManifestDiffRequest(r *http.Request, maxBytes int64) (*models.ManifestDiffRequest, error) {
+	var req models.ManifestDiffRequest
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	}
+
+	if err := r.ParseMultipartForm(maxBytes); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if req.Manifest1, req.Name1, err = readManifestPart(r, "manifest1"); err != nil {
+		return nil, err
+	}
+	if req.Manifest2, req.Name2, err = readManifestPart(r, "manifest2"); err != nil {
+		return nil, err
+	}
+
+	// Explicit names take precedence over the uploaded file names
+	if name := r.FormValue("name1"); name != "" {
+		req.Name1 = name
+	}
+	if name := r.FormValue("name2"); name != "" {
+		req.Name2 = name
+	}
+
+	if value := r.FormValue("ignoreLabels"); value != "" {
+		if req.IgnoreLabels, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid ignoreLabels: %w", err)
+		}
+	}
+	if value := r.FormValue("includeObjects"); value != "" {
+		if req.IncludeObjects, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid includeObjects: %w", err)
+		}
+	}
+	if value := r.FormValue("contextLines"); value != "" {
+		contextLines, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid contextLines: %w", err)
+		}
+		if contextLines < 0 || contextLines > diff.MaxContextLines {
+			return nil, fmt.Errorf("invalid contextLines: must be between 0 and %d", diff.MaxContextLines)
+		}
+		req.ContextLines = &contextLines
+	}
+	req.SecretHandling = r.FormValue("secretHandling")
+	for _, kinds := range r.MultipartForm.Value["suppressKinds"] {
+		for _, kind := range strings.Split(kinds, ",") {
+			if kind = strings.TrimSpace(kind); kind != "" {
+				req.SuppressKinds = append(req.SuppressKinds, kind)
+			}
+		}
+	}
+	if value := r.FormValue("suppressRegex"); value != "" {
+		req.SuppressRegex = &value
+	}
+
+	return &req, nil
+}
+
+// readManifestPart returns the content and file name of a manifest form part.
+// The part may be an uploaded file or a plain form field.
+func readManifestPart(r *http.Request, field string) (string, string, error) {
+	file, header, err := r.FormFile(field)
+	if err == http.ErrMissingFile {
+		return r.FormValue(field), "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", field, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", field, err)
+	}
+	return string(data), header.Filename, nil
+}
@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SetChart records the chart metadata diff and appends it to the raw output
+func (r *DiffResult) SetChart(chart *ChartDiff) {
+	if chart == nil || len(chart.Changes) == 0 && len(chart.Dependencies) == 0 {
+		return
+	}
+	r.Chart = chart
+
+	var sb strings.Builder
+	sb.WriteString("=== Chart ===\n")
+	for _, change := range chart.Changes {
+		sb.WriteString(fmt.Sprintf("  %s%s\n", change.Field, formatVersionBump(change.VersionBump, change.Downgrade)))
+		if change.Before != nil {
+			sb.WriteString(fmt.Sprintf("    - %v\n", formatChartValue(change.Before)))
+		}
+		if change.After != nil {
+			sb.WriteString(fmt.Sprintf("    + %v\n", formatChartValue(change.After)))
+		}
+	}
+	for _, dep := range chart.Dependencies {
+		switch dep.ChangeType {
+		case ChangeTypeAdded:
+			sb.WriteString(fmt.Sprintf("  dependency %s [added] %s\n", dep.Name, dep.AfterVersion))
+		case ChangeTypeRemoved:
+			sb.WriteString(fmt.Sprintf("  dependency %s [removed] %s\n", dep.Name, dep.BeforeVersion))
+		default:
+			sb.WriteString(fmt.Sprintf("  dependency %s [modified] %s -> %s%s\n",
+				dep.Name, dep.BeforeVersion, dep.AfterVersion, formatVersionBump(dep.VersionBump, dep.Downgrade)))
+			if dep.BeforeRepository != dep.AfterRepository {
+				sb.WriteString(fmt.Sprintf("    repository %s -> %s\n", dep.BeforeRepository, dep.AfterRepository))
+			}
+		}
+	}
+	sb.WriteString("\n")
+
+	r.Raw += sb.String()
+}
+
+// formatVersionBump formats a version bump classification for the raw output
+func formatVersionBump(bump string, downgrade bool) string {
+	switch {
+	case bump == "":
+		return ""
+	case downgrade:
+		return fmt.Sprintf(" (%s downgrade)", bump)
+	default:
+		return fmt.Sprintf(" (%s)", bump)
+	}
+}
+
+// formatChartValue formats a Chart.yaml value for the raw output, using JSON for lists and maps
+func formatChartValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
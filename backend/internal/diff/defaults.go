@@ -0,0 +1,327 @@
+package diff
+
+import (
+	"reflect"
+)
+
+// defaultRule removes fields that hold the value the API server defaults them to,
+// so that a chart spelling out a default is not reported as a change.
+// apply returns whether it removed anything from the resource.
+type defaultRule struct {
+	name  string
+	apply func(r *Resource) bool
+}
+
+// defaultRules are the API defaults stripped before diffing, in the order they are applied
+var defaultRules = []defaultRule{
+	{"containerPortProtocol", func(r *Resource) bool {
+		return forEachContainer(r, func(c map[string]interface{}) bool {
+			return forEachListItem(c, "ports", func(p map[string]interface{}) bool {
+				return removeDefault(p, "protocol", "TCP")
+			})
+		})
+	}},
+	{"terminationMessagePath", func(r *Resource) bool {
+		return forEachContainer(r, func(c map[string]interface{}) bool {
+			return removeDefault(c, "terminationMessagePath", "/dev/termination-log")
+		})
+	}},
+	{"terminationMessagePolicy", func(r *Resource) bool {
+		return forEachContainer(r, func(c map[string]interface{}) bool {
+			return removeDefault(c, "terminationMessagePolicy", "File")
+		})
+	}},
+	{"probeDefaults", func(r *Resource) bool {
+		return forEachContainer(r, func(c map[string]interface{}) bool {
+			removed := false
+			for _, probe := range []string{"livenessProbe", "readinessProbe", "startupProbe"} {
+				p, ok := c[probe].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				removed = removeDefault(p, "timeoutSeconds", 1.0) || removed
+				removed = removeDefault(p, "periodSeconds", 10.0) || removed
+				removed = removeDefault(p, "successThreshold", 1.0) || removed
+				removed = removeDefault(p, "failureThreshold", 3.0) || removed
+			}
+			return removed
+		})
+	}},
+	{"podRestartPolicy", func(r *Resource) bool {
+		// Jobs have no restartPolicy default, Always is not even allowed there
+		if r.Kind == "Job" || r.Kind == "CronJob" {
+			return false
+		}
+		return forEachPodSpec(r, func(spec map[string]interface{}) bool {
+			return removeDefault(spec, "restartPolicy", "Always")
+		})
+	}},
+	{"podDNSPolicy", func(r *Resource) bool {
+		return forEachPodSpec(r, func(spec map[string]interface{}) bool {
+			return removeDefault(spec, "dnsPolicy", "ClusterFirst")
+		})
+	}},
+	{"podSchedulerName", func(r *Resource) bool {
+		return forEachPodSpec(r, func(spec map[string]interface{}) bool {
+			return removeDefault(spec, "schedulerName", "default-scheduler")
+		})
+	}},
+	{"podTerminationGracePeriod", func(r *Resource) bool {
+		return forEachPodSpec(r, func(spec map[string]interface{}) bool {
+			return removeDefault(spec, "terminationGracePeriodSeconds", 30.0)
+		})
+	}},
+	{"revisionHistoryLimit", func(r *Resource) bool {
+		switch r.Kind {
+		case "Deployment", "StatefulSet", "DaemonSet":
+			return removeDefault(r.Spec, "revisionHistoryLimit", 10.0)
+		}
+		return false
+	}},
+	{"progressDeadlineSeconds", func(r *Resource) bool {
+		return r.Kind == "Deployment" && removeDefault(r.Spec, "progressDeadlineSeconds", 600.0)
+	}},
+	{"deploymentStrategy", func(r *Resource) bool {
+		if r.Kind != "Deployment" {
+			return false
+		}
+		strategy, ok := r.Spec["strategy"].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		removed := removeDefault(strategy, "type", "RollingUpdate")
+		if _, hasType := strategy["type"]; hasType {
+			return removed
+		}
+		if rollingUpdate, ok := strategy["rollingUpdate"].(map[string]interface{}); ok {
+			removed = removeDefault(rollingUpdate, "maxSurge", "25%") || removed
+			removed = removeDefault(rollingUpdate, "maxUnavailable", "25%") || removed
+			removeIfEmpty(strategy, "rollingUpdate")
+		}
+		removeIfEmpty(r.Spec, "strategy")
+		return removed
+	}},
+	{"statefulSetPodManagementPolicy", func(r *Resource) bool {
+		return r.Kind == "StatefulSet" && removeDefault(r.Spec, "podManagementPolicy", "OrderedReady")
+	}},
+	{"jobBackoffLimit", func(r *Resource) bool {
+		return r.Kind == "Job" && removeDefault(r.Spec, "backoffLimit", 6.0)
+	}},
+	{"serviceType", func(r *Resource) bool {
+		return r.Kind == "Service" && removeDefault(r.Spec, "type", "ClusterIP")
+	}},
+	{"serviceSessionAffinity", func(r *Resource) bool {
+		return r.Kind == "Service" && removeDefault(r.Spec, "sessionAffinity", "None")
+	}},
+	{"servicePortProtocol", func(r *Resource) bool {
+		return r.Kind == "Service" && forEachListItem(r.Spec, "ports", func(p map[string]interface{}) bool {
+			return removeDefault(p, "protocol", "TCP")
+		})
+	}},
+	{"servicePortTargetPort", func(r *Resource) bool {
+		return r.Kind == "Service" && forEachListItem(r.Spec, "ports", func(p map[string]interface{}) bool {
+			port, ok1 := intOrStringValue(p["port"])
+			target, ok2 := intOrStringValue(p["targetPort"])
+			if !ok1 || !ok2 || port != target {
+				return false
+			}
+			delete(p, "targetPort")
+			return true
+		})
+	}},
+	{"secretType", func(r *Resource) bool {
+		return r.Kind == "Secret" && removeDefault(r.Other, "type", "Opaque")
+	}},
+}
+
+// pullPolicyRule is the name of the imagePullPolicy rule, which needs both sides
+// and is applied by normalizePullPolicies rather than listed in defaultRules
+const pullPolicyRule = "imagePullPolicy"
+
+// normalizeDefaults strips well-known API server defaults from the resources of
+// both sides and records the name of every rule that changed something in applied
+func normalizeDefaults(resources1, resources2 []Resource, applied map[string]bool) {
+	for _, resources := range [][]Resource{resources1, resources2} {
+		for i := range resources {
+			for _, rule := range defaultRules {
+				if rule.apply(&resources[i]) {
+					applied[rule.name] = true
+				}
+			}
+		}
+	}
+	if normalizePullPolicies(resources1, resources2) {
+		applied[pullPolicyRule] = true
+	}
+}
+
+// containerIdentity identifies a container across both sides by its resource,
+// its list and its name
+type containerIdentity struct {
+	resource ResourceKey
+	field    string
+	name     string
+}
+
+// normalizePullPolicies handles imagePullPolicy, whose default depends on the
+// image. Each container is paired with the container of the same name in the
+// resource of the same identity on the other side. Where both run the same image,
+// a default policy is stripped like any other default. Where the image changes,
+// the effective policy is filled in on both sides instead, so that an implied
+// change from Always to IfNotPresent is reported and an explicit policy that
+// stays the same is not. Returns whether it stripped or filled in any policy.
+func normalizePullPolicies(resources1, resources2 []Resource) bool {
+	containers1 := containersByIdentity(resources1)
+	containers2 := containersByIdentity(resources2)
+
+	changed := false
+	for id, c1 := range containers1 {
+		if c2, ok := containers2[id]; ok {
+			changed = alignPullPolicies(c1, c2) || changed
+		} else {
+			changed = stripPullPolicy(c1) || changed
+		}
+	}
+	for id, c2 := range containers2 {
+		if _, ok := containers1[id]; !ok {
+			changed = stripPullPolicy(c2) || changed
+		}
+	}
+	return changed
+}
+
+// containersByIdentity indexes the containers, init containers and ephemeral
+// containers of all resources with a pod spec
+func containersByIdentity(resources []Resource) map[containerIdentity]map[string]interface{} {
+	containers := make(map[containerIdentity]map[string]interface{})
+	for i := range resources {
+		key := GetResourceKey(resources[i])
+		forEachPodSpec(&resources[i], func(spec map[string]interface{}) bool {
+			for _, field := range []string{"containers", "initContainers", "ephemeralContainers"} {
+				forEachListItem(spec, field, func(c map[string]interface{}) bool {
+					if name, ok := c["name"].(string); ok {
+						containers[containerIdentity{resource: key, field: field, name: name}] = c
+					}
+					return false
+				})
+			}
+			return false
+		})
+	}
+	return containers
+}
+
+// alignPullPolicies normalizes the imagePullPolicy of a container present on both sides
+func alignPullPolicies(c1, c2 map[string]interface{}) bool {
+	image1, ok1 := c1["image"].(string)
+	image2, ok2 := c2["image"].(string)
+	if ok1 && ok2 && ParseImageReference(image1) != ParseImageReference(image2) {
+		filled1 := fillDefault(c1, "imagePullPolicy", defaultPullPolicy(image1))
+		filled2 := fillDefault(c2, "imagePullPolicy", defaultPullPolicy(image2))
+		return filled1 || filled2
+	}
+	stripped1 := stripPullPolicy(c1)
+	stripped2 := stripPullPolicy(c2)
+	return stripped1 || stripped2
+}
+
+// stripPullPolicy removes a container's imagePullPolicy if it is the default for its image
+func stripPullPolicy(c map[string]interface{}) bool {
+	image, ok := c["image"].(string)
+	if !ok {
+		return false
+	}
+	return removeDefault(c, "imagePullPolicy", defaultPullPolicy(image))
+}
+
+// forEachPodSpec calls fn with the pod spec of a Pod or of a workload's pod template.
+// Returns whether any call returned true.
+func forEachPodSpec(r *Resource, fn func(spec map[string]interface{}) bool) bool {
+	var spec map[string]interface{}
+	switch r.Kind {
+	case "Pod":
+		spec = r.Spec
+	case "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job", "ReplicationController":
+		spec = nestedMap(r.Spec, "template", "spec")
+	case "CronJob":
+		spec = nestedMap(r.Spec, "jobTemplate", "spec", "template", "spec")
+	}
+	if spec == nil {
+		return false
+	}
+	return fn(spec)
+}
+
+// forEachContainer calls fn with every container, init container and ephemeral
+// container of a resource's pod spec. Returns whether any call returned true.
+func forEachContainer(r *Resource, fn func(c map[string]interface{}) bool) bool {
+	return forEachPodSpec(r, func(spec map[string]interface{}) bool {
+		removed := false
+		for _, field := range []string{"containers", "initContainers", "ephemeralContainers"} {
+			removed = forEachListItem(spec, field, fn) || removed
+		}
+		return removed
+	})
+}
+
+// forEachListItem calls fn with every object in the list m[field].
+// Returns whether any call returned true.
+func forEachListItem(m map[string]interface{}, field string, fn func(item map[string]interface{}) bool) bool {
+	list, _ := m[field].([]interface{})
+	removed := false
+	for _, item := range list {
+		if obj, ok := item.(map[string]interface{}); ok {
+			removed = fn(obj) || removed
+		}
+	}
+	return removed
+}
+
+// nestedMap returns the map found by following fields from m, or nil
+func nestedMap(m map[string]interface{}, fields ...string) map[string]interface{} {
+	for _, field := range fields {
+		next, ok := m[field].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		m = next
+	}
+	return m
+}
+
+// removeDefault deletes m[field] if it holds the default value.
+// Numbers are parsed as float64, so numeric defaults must be given as floats.
+func removeDefault(m map[string]interface{}, field string, def interface{}) bool {
+	value, ok := m[field]
+	if !ok || !reflect.DeepEqual(value, def) {
+		return false
+	}
+	delete(m, field)
+	return true
+}
+
+// fillDefault sets m[field] to the default value if it is not set
+func fillDefault(m map[string]interface{}, field string, def interface{}) bool {
+	if _, ok := m[field]; ok {
+		return false
+	}
+	m[field] = def
+	return true
+}
+
+// removeIfEmpty deletes m[field] if it is an empty map
+func removeIfEmpty(m map[string]interface{}, field string) {
+	if value, ok := m[field].(map[string]interface{}); ok && len(value) == 0 {
+		delete(m, field)
+	}
+}
+
+// defaultPullPolicy returns the imagePullPolicy the API server sets for an image:
+// Always for an untagged or :latest image without a digest, IfNotPresent otherwise
+func defaultPullPolicy(image string) string {
+	ref := ParseImageReference(image)
+	if ref.Digest == "" && (ref.Tag == "" || ref.Tag == "latest") {
+		return "Always"
+	}
+	return "IfNotPresent"
+}
@@ -0,0 +1,196 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDefaults_ExplicitDefaultsAreNotChanges(t *testing.T) {
+	implicit := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx:1.25
+          ports:
+            - containerPort: 80
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: 80
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+    - port: 80
+`
+	explicit := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  revisionHistoryLimit: 10
+  progressDeadlineSeconds: 600
+  strategy:
+    type: RollingUpdate
+    rollingUpdate:
+      maxSurge: 25%
+      maxUnavailable: 25%
+  template:
+    spec:
+      restartPolicy: Always
+      dnsPolicy: ClusterFirst
+      schedulerName: default-scheduler
+      terminationGracePeriodSeconds: 30
+      containers:
+        - name: app
+          image: nginx:1.25
+          imagePullPolicy: IfNotPresent
+          terminationMessagePath: /dev/termination-log
+          terminationMessagePolicy: File
+          ports:
+            - containerPort: 80
+              protocol: TCP
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: 80
+            timeoutSeconds: 1
+            periodSeconds: 10
+            successThreshold: 1
+            failureThreshold: 3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: ClusterIP
+  sessionAffinity: None
+  ports:
+    - port: 80
+      targetPort: 80
+      protocol: TCP
+`
+
+	result, err := NewEngine().Compare(implicit, explicit)
+	require.NoError(t, err)
+	assert.Empty(t, result.Resources)
+
+	for _, rule := range []string{
+		"containerPortProtocol", "imagePullPolicy", "terminationMessagePath", "terminationMessagePolicy",
+		"probeDefaults", "podRestartPolicy", "podDNSPolicy", "podSchedulerName", "podTerminationGracePeriod",
+		"revisionHistoryLimit", "progressDeadlineSeconds", "deploymentStrategy",
+		"serviceType", "serviceSessionAffinity", "servicePortProtocol", "servicePortTargetPort",
+	} {
+		assert.Contains(t, result.Metadata.NormalizationRules, "normalizeDefaults:"+rule)
+	}
+	assert.NotContains(t, result.Metadata.NormalizationRules, "normalizeDefaults:secretType")
+}
+
+func TestNormalizeDefaults_NonDefaultsAreKept(t *testing.T) {
+	before := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx:latest
+`
+	after := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  strategy:
+    type: Recreate
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx:latest
+          imagePullPolicy: IfNotPresent
+`
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	assert.NotNil(t, findChange(changes, "spec.strategy"))
+	assert.NotNil(t, findChange(changes, "spec.template.spec.containers[name=app].imagePullPolicy"),
+		"IfNotPresent is not the default for a :latest image")
+	assert.Len(t, changes, 2)
+	assert.Equal(t, []string{"secretHandling:suppress"}, result.Metadata.NormalizationRules)
+}
+
+func TestDefaultPullPolicy(t *testing.T) {
+	tests := map[string]string{
+		"nginx":                         "Always",
+		"nginx:latest":                  "Always",
+		"nginx:1.25":                    "IfNotPresent",
+		"registry:5000/team/app":        "Always",
+		"registry:5000/team/app:v2":     "IfNotPresent",
+		"nginx@sha256:0123456789abcdef": "IfNotPresent",
+	}
+	for image, expected := range tests {
+		assert.Equal(t, expected, defaultPullPolicy(image), image)
+	}
+}
+
+func TestNormalizeDefaults_PullPolicyFollowsImageChange(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+    - name: app
+      image: %s
+%s`
+
+	// An explicit policy that stays the same is not a change, even though it is
+	// the default for one of the images
+	result, err := NewEngine().Compare(
+		fmt.Sprintf(manifest, "x:latest", "      imagePullPolicy: Always\n"),
+		fmt.Sprintf(manifest, "x:1.0", "      imagePullPolicy: Always\n"),
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+	require.Len(t, result.Resources[0].Changes, 1)
+	assert.Equal(t, "spec.containers[name=app].image", result.Resources[0].Changes[0].Path)
+
+	// The implied policy changes from Always to IfNotPresent with the image
+	result, err = NewEngine().Compare(fmt.Sprintf(manifest, "x:latest", ""), fmt.Sprintf(manifest, "x:1.0", ""))
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+	policy := findChange(result.Resources[0].Changes, "spec.containers[name=app].imagePullPolicy")
+	require.NotNil(t, policy)
+	assert.Equal(t, OpReplace, policy.Op)
+	assert.Equal(t, "Always", policy.Before)
+	assert.Equal(t, "IfNotPresent", policy.After)
+	assert.Contains(t, result.Metadata.NormalizationRules, "normalizeDefaults:imagePullPolicy")
+
+	// With the same image, the spelled-out default is stripped
+	result, err = NewEngine().Compare(fmt.Sprintf(manifest, "x:1.0", ""), fmt.Sprintf(manifest, "x:1.0", "      imagePullPolicy: IfNotPresent\n"))
+	require.NoError(t, err)
+	assert.Empty(t, result.Resources)
+}
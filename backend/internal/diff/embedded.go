@@ -0,0 +1,183 @@
+package diff
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Embedded document formats recognized in ConfigMap data values
+const (
+	FormatYAML       = "yaml"
+	FormatJSON       = "json"
+	FormatProperties = "properties"
+)
+
+// formatsByExtension maps data key extensions to the format of their value
+var formatsByExtension = map[string]string{
+	".yaml":       FormatYAML,
+	".yml":        FormatYAML,
+	".json":       FormatJSON,
+	".properties": FormatProperties,
+}
+
+// compareConfigMapData compares the data of two ConfigMaps. Values holding a YAML,
+// JSON or properties document, recognized by the key's extension or by their
+// content, are parsed and diffed field by field below "data.<key>/", with dots
+// in the key escaped, e.g. "data.application\.yaml/server.port". Other values are
+// replaced, with a line diff if they span multiple lines.
+func (e *Engine) compareConfigMapData(data1, data2 map[string]interface{}) []Change {
+	keys := make([]string, 0, len(data1)+len(data2))
+	for key := range data1 {
+		keys = append(keys, key)
+	}
+	for key := range data2 {
+		if _, ok := data1[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]Change, 0)
+	for _, key := range keys {
+		path := resourcePath("ConfigMap").child("data").child(key)
+		val1, exists1 := data1[key]
+		val2, exists2 := data2[key]
+
+		switch {
+		case exists1 && !exists2:
+			changes = append(changes, e.createChange(OpRemove, path, val1, nil))
+		case !exists1 && exists2:
+			changes = append(changes, e.createChange(OpAdd, path, nil, val2))
+		case !reflect.DeepEqual(val1, val2):
+			changes = append(changes, e.compareDataValue(path, key, val1, val2)...)
+		}
+	}
+	return changes
+}
+
+// compareDataValue compares two different values of the ConfigMap data key found at path
+func (e *Engine) compareDataValue(path fieldPath, key string, val1, val2 interface{}) []Change {
+	text1, ok1 := val1.(string)
+	text2, ok2 := val2.(string)
+	if !ok1 || !ok2 {
+		return []Change{e.createChange(OpReplace, path, val1, val2)}
+	}
+
+	if format := embeddedFormat(key, text1, text2); format != "" {
+		doc1, ok1 := parseEmbedded(format, text1)
+		doc2, ok2 := parseEmbedded(format, text2)
+		if ok1 && ok2 {
+			// Reformatting or comment changes leave the parsed documents equal
+			return e.compareValues(path.document(), "", doc1, doc2)
+		}
+	}
+
+	return []Change{e.createChange(OpReplace, path, val1, val2)}
+}
+
+// embeddedFormat returns the format of a data key's values from the key's
+// extension or, failing that, by sniffing both values. Returns "" for plain text.
+func embeddedFormat(key, text1, text2 string) string {
+	if format, ok := formatsByExtension[strings.ToLower(filepath.Ext(key))]; ok {
+		return format
+	}
+	for _, format := range []string{FormatJSON, FormatYAML, FormatProperties} {
+		if looksLike(format, text1) && looksLike(format, text2) {
+			return format
+		}
+	}
+	return ""
+}
+
+// looksLike reports whether text is a multi-line document of the given format
+func looksLike(format, text string) bool {
+	text = strings.TrimSpace(text)
+	if !strings.Contains(text, "\n") {
+		return false
+	}
+	switch format {
+	case FormatJSON:
+		return (strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[")) && json.Valid([]byte(text))
+	case FormatYAML:
+		_, ok := parseEmbedded(FormatYAML, text)
+		return ok
+	case FormatProperties:
+		for _, line := range strings.Split(text, "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !isPropertiesComment(line) && !strings.Contains(line, "=") {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// parseEmbedded parses a document into a map or a list. Returns false if the
+// text does not parse or holds a scalar, which is diffed as text instead.
+func parseEmbedded(format, text string) (interface{}, bool) {
+	var doc interface{}
+	switch format {
+	case FormatJSON:
+		if err := json.Unmarshal([]byte(text), &doc); err != nil {
+			return nil, false
+		}
+	case FormatYAML:
+		// Only the first document of a stream would be parsed
+		if strings.Contains(strings.TrimPrefix(strings.TrimSpace(text), "---"), "\n---") {
+			return nil, false
+		}
+		if err := yaml.Unmarshal([]byte(text), &doc); err != nil {
+			return nil, false
+		}
+	case FormatProperties:
+		doc = parseProperties(text)
+	}
+
+	switch doc.(type) {
+	case map[string]interface{}, []interface{}:
+		return doc, true
+	}
+	return nil, false
+}
+
+// parseProperties parses a Java properties file into a flat map of string values.
+// Keys are separated from values by "=", ":" or whitespace, and lines ending in a
+// backslash continue on the next line.
+func parseProperties(text string) map[string]interface{} {
+	props := make(map[string]interface{})
+	var logical string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimLeft(strings.TrimRight(line, "\r"), " \t")
+		if logical == "" && (line == "" || isPropertiesComment(line)) {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") {
+			logical += strings.TrimSuffix(line, "\\")
+			continue
+		}
+		logical += line
+
+		key, value := logical, ""
+		if i := strings.IndexAny(logical, "=: \t"); i >= 0 {
+			key = logical[:i]
+			value = strings.TrimLeft(logical[i:], " \t")
+			if value != "" && (value[0] == '=' || value[0] == ':') {
+				value = strings.TrimLeft(value[1:], " \t")
+			}
+		}
+		props[key] = value
+		logical = ""
+	}
+	return props
+}
+
+// isPropertiesComment reports whether a trimmed properties line is a comment
+func isPropertiesComment(line string) bool {
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!")
+}
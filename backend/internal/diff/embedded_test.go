@@ -0,0 +1,139 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configMap renders a ConfigMap with a single data key holding value
+func configMap(key, value string) string {
+	var sb strings.Builder
+	sb.WriteString("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app-config\ndata:\n")
+	sb.WriteString("  " + key + ": |\n")
+	for _, line := range strings.Split(strings.TrimSuffix(value, "\n"), "\n") {
+		sb.WriteString("    " + line + "\n")
+	}
+	return sb.String()
+}
+
+func TestCompareConfigMapData_EmbeddedYAML(t *testing.T) {
+	before := configMap("application.yaml", "server:\n  port: 8080\n  host: 0.0.0.0\nlogging:\n  level: info\n")
+	after := configMap("application.yaml", "# Tuned for prod\nserver:\n  host: 0.0.0.0\n  port: 9090\nlogging:\n  level: info\nfeatures: [a, b]\n")
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	require.Len(t, changes, 2, "reordering and comments are not changes")
+
+	port := findChange(changes, `data.application\.yaml#server.port`)
+	require.NotNil(t, port)
+	assert.Equal(t, OpReplace, port.Op)
+	assert.Equal(t, float64(8080), port.Before)
+	assert.Equal(t, float64(9090), port.After)
+	assert.Equal(t, []PathToken{"data", "application.yaml", "server", "port"}, port.PathTokens)
+	assert.Equal(t, "/data/application.yaml", port.Pointer)
+	assert.Equal(t, "/server/port", port.EmbeddedPointer)
+
+	features := findChange(changes, `data.application\.yaml#features`)
+	require.NotNil(t, features)
+	assert.Equal(t, OpAdd, features.Op)
+}
+
+func TestCompareConfigMapData_EmbeddedFieldsAreNotClassified(t *testing.T) {
+	before := configMap("app.yaml", "image: nginx:1.25\nreplicas: 2\n")
+	after := configMap("app.yaml", "image: nginx:2.0\nreplicas: 3\n")
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	require.Len(t, changes, 2)
+	for _, change := range changes {
+		assert.Empty(t, change.SemanticType, change.Path)
+		assert.Equal(t, "config", change.ChangeCategory, change.Path)
+		assert.Equal(t, "medium", change.Importance, change.Path)
+		assert.Empty(t, change.Flags, change.Path)
+		assert.Nil(t, change.Image, change.Path)
+	}
+}
+
+func TestCompareConfigMapData_EmbeddedJSONAndProperties(t *testing.T) {
+	before := configMap("config.json", `{"retries": 3, "endpoints": ["a", "b"]}`) +
+		"  log4j.properties: |\n    log4j.rootLogger=INFO, stdout\n    log4j.appender.stdout=org.apache.log4j.ConsoleAppender\n"
+	after := configMap("config.json", `{"endpoints": ["a", "c"], "retries": 3}`) +
+		"  log4j.properties: |\n    # quieter\n    log4j.rootLogger = WARN, stdout\n    log4j.appender.stdout=org.apache.log4j.ConsoleAppender\n"
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	require.Len(t, changes, 2)
+
+	endpoint := findChange(changes, `data.config\.json#endpoints.1`)
+	require.NotNil(t, endpoint)
+	assert.Equal(t, "b", endpoint.Before)
+	assert.Equal(t, "c", endpoint.After)
+
+	logger := findChange(changes, `data.log4j\.properties#log4j\.rootLogger`)
+	require.NotNil(t, logger)
+	assert.Equal(t, "INFO, stdout", logger.Before)
+	assert.Equal(t, "WARN, stdout", logger.After)
+}
+
+func TestCompareConfigMapData_SniffedFormat(t *testing.T) {
+	before := configMap("settings", "{\n  \"debug\": false\n}\n")
+	after := configMap("settings", "{\n  \"debug\": true\n}\n")
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	require.Len(t, changes, 1)
+	assert.Equal(t, "data.settings#debug", changes[0].Path)
+}
+
+func TestCompareConfigMapData_TextFallback(t *testing.T) {
+	script := "#!/bin/sh\nset -e\necho starting\nexec /app --port 8080\n"
+	before := configMap("entrypoint.sh", script)
+	after := configMap("entrypoint.sh", strings.Replace(script, "8080", "9090", 1))
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	require.Len(t, changes, 1)
+	assert.Equal(t, `data.entrypoint\.sh`, changes[0].Path)
+	require.Len(t, changes[0].Hunks, 1)
+	assert.Contains(t, changes[0].Hunks[0].Lines, "-exec /app --port 8080")
+	assert.Contains(t, changes[0].Hunks[0].Lines, "+exec /app --port 9090")
+	assert.Contains(t, result.Raw, "    -exec /app --port 8080\n    +exec /app --port 9090\n")
+
+	// An unparsable document falls back to the line diff too
+	before = configMap("app.yaml", "server:\n  port: 8080\n")
+	after = configMap("app.yaml", "server:\n  port: [8080\n")
+	result, err = NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+	assert.Equal(t, `data.app\.yaml`, result.Resources[0].Changes[0].Path)
+	assert.NotEmpty(t, result.Resources[0].Changes[0].Hunks)
+}
+
+func TestParseProperties(t *testing.T) {
+	props := parseProperties("# comment\n! also a comment\n\na=1\nb : 2\nc 3\nlong=first \\\n    second\nempty=\n")
+	assert.Equal(t, map[string]interface{}{
+		"a":     "1",
+		"b":     "2",
+		"c":     "3",
+		"long":  "first second",
+		"empty": "",
+	}, props)
+}
@@ -5,13 +5,14 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"reflect"
+	"io"
+	"regexp"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -24,6 +25,20 @@ type Engine struct {
 	IgnoreLabels      bool
 	IgnoreAnnotations bool
 
+	// Suppression rules
+	SuppressKinds []string       // Resource kinds excluded from the diff entirely
+	SuppressRegex *regexp.Regexp // Changes whose path or rendered line matches are dropped
+
+	// SecretHandling controls how Secret values are exposed: suppress (default), show or decode
+	SecretHandling string
+
+	// IncludeObjects adds the full object of added and removed resources to their diffs
+	IncludeObjects bool
+
+	// ContextLines is the number of unchanged lines shown around changes to multi-line
+	// strings; DefaultContextLines if nil
+	ContextLines *int
+
 	// Metadata for traceability
 	LeftSource  *SourceMetadata
 	RightSource *SourceMetadata
@@ -36,21 +51,42 @@ func NewEngine() *Engine {
 
 // Compare compares two YAML manifests and returns a structured diff
 func (e *Engine) Compare(manifest1, manifest2 string) (*DiffResult, error) {
+	return e.CompareReaders(strings.NewReader(manifest1), strings.NewReader(manifest2))
+}
+
+// CompareReaders compares two YAML manifest streams and returns a structured diff.
+// Documents that cannot be parsed are reported in ParseWarnings.
+func (e *Engine) CompareReaders(manifest1, manifest2 io.Reader) (*DiffResult, error) {
 	// Parse both manifests
-	resources1, err := ParseManifests(manifest1)
+	resources1, parseWarnings1, err := ParseManifestStream(manifest1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse manifest1: %w", err)
 	}
 
-	resources2, err := ParseManifests(manifest2)
+	resources2, parseWarnings2, err := ParseManifestStream(manifest2)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse manifest2: %w", err)
 	}
 
+	// Redact or decode Secret values before anything else sees them
+	e.applySecretHandling(resources1)
+	e.applySecretHandling(resources2)
+
+	// Strip API server defaults on both sides, so that spelling one out is not a change
+	appliedDefaults := make(map[string]bool)
+	normalizeDefaults(resources1, resources2, appliedDefaults)
+
+	// Colliding resources are reported, since only the last one is kept in the maps
+	identityConflicts1 := FindIdentityConflicts(resources1)
+	identityConflicts2 := FindIdentityConflicts(resources2)
+
 	// Create resource maps
 	map1 := GetResourcesByKey(resources1)
 	map2 := GetResourcesByKey(resources2)
 
+	// Drop suppressed kinds before matching
+	suppressedResources := e.filterSuppressedKinds(map1, map2)
+
 	// Collect all keys
 	allKeys := make(map[ResourceKey]bool)
 	for key := range map1 {
@@ -77,7 +113,7 @@ func (e *Engine) Compare(manifest1, manifest2 string) (*DiffResult, error) {
 				Left:  e.getSourceMetadata(true),
 				Right: e.getSourceMetadata(false),
 			},
-			NormalizationRules: e.getNormalizationRules(),
+			NormalizationRules: e.getNormalizationRules(appliedDefaults),
 		},
 		Resources: make([]ResourceDiff, 0),
 		Stats: &Stats{
@@ -87,6 +123,10 @@ func (e *Engine) Compare(manifest1, manifest2 string) (*DiffResult, error) {
 		Summary: Summary{}, // Legacy field
 	}
 
+	if len(e.SuppressKinds) > 0 || e.SuppressRegex != nil {
+		result.Stats.Suppressed = &StatsSuppressed{Resources: suppressedResources}
+	}
+
 	totalChanges := 0
 
 	for _, key := range sortedKeys {
@@ -107,8 +147,12 @@ func (e *Engine) Compare(manifest1, manifest2 string) (*DiffResult, error) {
 			result.Summary.Added++ // Legacy
 		} else {
 			// Resource exists in both, check for modifications
-			changes := e.compareResources(resource1, resource2)
+			changes, suppressedChanges := e.filterSuppressedChanges(e.compareResources(resource1, resource2))
+			if suppressedChanges > 0 {
+				result.Stats.Suppressed.Changes += suppressedChanges
+			}
 			if len(changes) > 0 {
+				e.flagSecretChanges(resource2, changes)
 				resourceDiff = e.createResourceDiff(key, resource1, resource2, ChangeTypeModified)
 				resourceDiff.Changes = changes
 
@@ -130,11 +174,21 @@ func (e *Engine) Compare(manifest1, manifest2 string) (*DiffResult, error) {
 		result.Resources = append(result.Resources, resourceDiff)
 	}
 
+	// Removed and added resources that only changed apiVersion are one modified object;
+	// those with nearly identical content were renamed or moved
+	totalChanges += e.pairAPIVersionChanges(result, map1, map2)
+	totalChanges += e.detectRenames(result, map1, map2)
+
 	result.Stats.Changes.Total = totalChanges
+	result.Stats.BySubchart = subchartStats(result.Resources)
 	result.Summary.Total = result.Summary.Added + result.Summary.Removed + result.Summary.Modified // Legacy
 
 	// Generate raw diff output for backward compatibility
 	result.Raw = e.generateRawDiff(result)
+	result.addParseWarnings("left", parseWarnings1)
+	result.addParseWarnings("right", parseWarnings2)
+	result.addIdentityConflicts("left", identityConflicts1)
+	result.addIdentityConflicts("right", identityConflicts2)
 
 	return result, nil
 }
@@ -142,26 +196,31 @@ func (e *Engine) Compare(manifest1, manifest2 string) (*DiffResult, error) {
 // compareResources compares two resources and returns field-level diffs
 func (e *Engine) compareResources(r1, r2 Resource) []Change {
 	changes := make([]Change, 0)
+	root := resourcePath(r2.Kind)
 
 	// Compare metadata (excluding labels and annotations if configured)
 	if !e.IgnoreLabels {
-		changes = append(changes, e.compareStringMaps("metadata.labels", r1.Metadata.Labels, r2.Metadata.Labels)...)
+		changes = append(changes, e.compareStringMaps(root.child("metadata").child("labels"), r1.Metadata.Labels, r2.Metadata.Labels)...)
 	}
 	if !e.IgnoreAnnotations {
-		changes = append(changes, e.compareStringMaps("metadata.annotations", r1.Metadata.Annotations, r2.Metadata.Annotations)...)
+		changes = append(changes, e.compareStringMaps(root.child("metadata").child("annotations"), r1.Metadata.Annotations, r2.Metadata.Annotations)...)
 	}
 
 	// Compare other metadata fields
-	changes = append(changes, e.compareMaps("metadata", r1.Metadata.Other, r2.Metadata.Other)...)
+	changes = append(changes, e.compareMaps(root.child("metadata"), r1.Metadata.Other, r2.Metadata.Other)...)
 
 	// Compare spec
-	changes = append(changes, e.compareMaps("spec", r1.Spec, r2.Spec)...)
+	changes = append(changes, e.compareMaps(root.child("spec"), r1.Spec, r2.Spec)...)
 
-	// Compare data
-	changes = append(changes, e.compareMaps("data", r1.Data, r2.Data)...)
+	// Compare data; ConfigMap values often hold whole configuration files
+	if r1.Kind == "ConfigMap" && r2.Kind == "ConfigMap" {
+		changes = append(changes, e.compareConfigMapData(r1.Data, r2.Data)...)
+	} else {
+		changes = append(changes, e.compareMaps(root.child("data"), r1.Data, r2.Data)...)
+	}
 
 	// Compare other fields
-	changes = append(changes, e.compareMaps("", r1.Other, r2.Other)...)
+	changes = append(changes, e.compareMaps(root, r1.Other, r2.Other)...)
 
 	return changes
 }
@@ -187,11 +246,30 @@ func (e *Engine) createResourceDiff(key ResourceKey, before, after Resource, cha
 		Fields:     []FieldDiff{},
 	}
 
-	// Calculate hashes for modified resources
-	if changeType == ChangeTypeModified || changeType == ChangeTypeRemoved {
+	// Hooks and sources are described by the current resource, or the removed one
+	if changeType == ChangeTypeRemoved {
+		rd.Hook = hookInfo(before)
+		rd.Source = before.Source
+	} else {
+		rd.Hook = hookInfo(after)
+		rd.Source = after.Source
+	}
+
+	// Added and removed resources have no field changes to show, so their content is included on request
+	if e.IncludeObjects {
+		switch changeType {
+		case ChangeTypeAdded:
+			rd.Object = resourceObject(after)
+		case ChangeTypeRemoved:
+			rd.Object = resourceObject(before)
+		}
+	}
+
+	// Calculate hashes for the sides the resource exists on
+	if changeType != ChangeTypeAdded {
 		rd.BeforeHash = e.calculateResourceHash(before)
 	}
-	if changeType == ChangeTypeModified || changeType == ChangeTypeAdded {
+	if changeType != ChangeTypeRemoved {
 		rd.AfterHash = e.calculateResourceHash(after)
 	}
 
@@ -221,8 +299,9 @@ func (e *Engine) getSourceMetadata(isLeft bool) SourceMetadata {
 	}
 }
 
-// getNormalizationRules returns the list of normalization rules applied
-func (e *Engine) getNormalizationRules() []string {
+// getNormalizationRules returns the list of normalization rules applied, including
+// the API default rules that removed a field from either side
+func (e *Engine) getNormalizationRules(appliedDefaults map[string]bool) []string {
 	rules := []string{}
 
 	if e.IgnoreLabels {
@@ -232,8 +311,17 @@ func (e *Engine) getNormalizationRules() []string {
 		rules = append(rules, "ignoreAnnotations")
 	}
 
-	// Always applied normalization
-	rules = append(rules, "normalizeDefaults")
+	for _, rule := range defaultRules {
+		if appliedDefaults[rule.name] {
+			rules = append(rules, "normalizeDefaults:"+rule.name)
+		}
+	}
+	if appliedDefaults[pullPolicyRule] {
+		rules = append(rules, "normalizeDefaults:"+pullPolicyRule)
+	}
+	rules = append(rules, "secretHandling:"+e.secretHandling())
+
+	rules = append(rules, e.suppressionRules()...)
 
 	return rules
 }
@@ -296,7 +384,7 @@ func (e *Engine) convertToFieldDiffs(changes []Change) []FieldDiff {
 }
 
 // compareStringMaps compares two string maps
-func (e *Engine) compareStringMaps(basePath string, map1, map2 map[string]string) []Change {
+func (e *Engine) compareStringMaps(basePath fieldPath, map1, map2 map[string]string) []Change {
 	// Convert string maps to interface maps for unified comparison
 	iMap1 := make(map[string]interface{}, len(map1))
 	iMap2 := make(map[string]interface{}, len(map2))
@@ -310,7 +398,7 @@ func (e *Engine) compareStringMaps(basePath string, map1, map2 map[string]string
 }
 
 // compareMaps compares two generic maps
-func (e *Engine) compareMaps(basePath string, map1, map2 map[string]interface{}) []Change {
+func (e *Engine) compareMaps(basePath fieldPath, map1, map2 map[string]interface{}) []Change {
 	changes := make([]Change, 0)
 
 	// Collect all keys
@@ -333,35 +421,39 @@ func (e *Engine) compareMaps(basePath string, map1, map2 map[string]interface{})
 		val1, exists1 := map1[key]
 		val2, exists2 := map2[key]
 
-		var path string
-		if basePath == "" {
-			path = key
-		} else {
-			path = basePath + "." + key
-		}
+		path := basePath.child(key)
 
 		if exists1 && !exists2 {
 			changes = append(changes, e.createChange(OpRemove, path, val1, nil))
 		} else if !exists1 && exists2 {
 			changes = append(changes, e.createChange(OpAdd, path, nil, val2))
-		} else if !e.deepEqual(val1, val2) {
-			// Check if both are maps - recurse
-			if m1, ok1 := val1.(map[string]interface{}); ok1 {
-				if m2, ok2 := val2.(map[string]interface{}); ok2 {
-					changes = append(changes, e.compareMaps(path, m1, m2)...)
-					continue
-				}
-			}
-
-			changes = append(changes, e.createChange(OpReplace, path, val1, val2))
+		} else if !e.deepEqual(path, val1, val2) {
+			changes = append(changes, e.compareValues(path, key, val1, val2)...)
 		}
 	}
 
 	return changes
 }
 
+// compareValues compares two differing values found at path under the given field name.
+// Maps and lists of the same shape are recursed into; anything else is a replace.
+func (e *Engine) compareValues(path fieldPath, field string, val1, val2 interface{}) []Change {
+	switch v1 := val1.(type) {
+	case map[string]interface{}:
+		if v2, ok := val2.(map[string]interface{}); ok {
+			return e.compareMaps(path, v1, v2)
+		}
+	case []interface{}:
+		if v2, ok := val2.([]interface{}); ok {
+			return e.compareLists(path, field, v1, v2)
+		}
+	}
+
+	return []Change{e.createChange(OpReplace, path, val1, val2)}
+}
+
 // createChange creates a Change object with semantic information
-func (e *Engine) createChange(op OpType, path string, before, after interface{}) Change {
+func (e *Engine) createChange(op OpType, path fieldPath, before, after interface{}) Change {
 	// Determine value for type inspection
 	value := after
 	if value == nil {
@@ -370,49 +462,53 @@ func (e *Engine) createChange(op OpType, path string, before, after interface{})
 
 	change := Change{
 		Op:             op,
-		Path:           path,
-		PathTokens:     e.pathToTokens(path),
+		Path:           path.display,
+		PathTokens:     path.tokens,
 		Before:         before,
 		After:          after,
 		ValueType:      getValueType(value),
-		SemanticType:   classifySemanticType(path),
-		ChangeCategory: classifyChangeCategory(path),
+		SemanticType:   classifySemanticType(path.tokens),
+		ChangeCategory: classifyChangeCategory(path.tokens),
 	}
+	change.Pointer, change.EmbeddedPointer = path.pointers()
 
-	change.Importance = determineImportance(path, change.SemanticType)
-	change.Flags = determineFlags(path, change.SemanticType)
-
-	return change
-}
-
-// pathToTokens converts a dot-notation path to typed tokens
-// Note: This simple implementation splits on dots without handling escaped dots.
-// Field names with literal dots are not currently supported but are rare in Kubernetes resources.
-// A future enhancement could use a proper JSON pointer parser if needed.
-func (e *Engine) pathToTokens(path string) []PathToken {
-	if path == "" {
-		return []PathToken{}
+	change.Importance = determineImportance(path.tokens, change.SemanticType)
+	change.Flags = determineFlags(path.tokens, change.SemanticType)
+	if op == OpReplace {
+		change.QuantityDelta = quantityDelta(path, before, after)
+		change.Hunks = e.textHunks(before, after)
 	}
 
-	parts := strings.Split(path, ".")
-	tokens := make([]PathToken, 0, len(parts))
-
-	for _, part := range parts {
-		// Try to parse as integer (array index)
-		if idx, err := strconv.Atoi(part); err == nil {
-			tokens = append(tokens, idx)
-		} else {
-			tokens = append(tokens, part)
+	// Image changes are rated by what changed instead of all being high
+	if op == OpReplace && change.SemanticType == "container.image" {
+		image1, ok1 := before.(string)
+		image2, ok2 := after.(string)
+		if ok1 && ok2 {
+			if change.Image = classifyImageChange(image1, image2); change.Image != nil {
+				change.Importance = change.Image.importance()
+			}
 		}
 	}
 
-	return tokens
+	return change
+}
+
+// textHunks returns the line diff of two strings if either spans multiple lines
+func (e *Engine) textHunks(before, after interface{}) []TextHunk {
+	text1, ok1 := before.(string)
+	text2, ok2 := after.(string)
+	if !ok1 || !ok2 || !strings.Contains(text1, "\n") && !strings.Contains(text2, "\n") {
+		return nil
+	}
+	return diffHunks(text1, text2, e.contextLines())
 }
 
-// deepEqual compares two values for equality
-func (e *Engine) deepEqual(v1, v2 interface{}) bool {
-	// Use reflect.DeepEqual for deep comparison
-	return reflect.DeepEqual(v1, v2)
+// contextLines returns the configured number of context lines, defaulting to DefaultContextLines
+func (e *Engine) contextLines() int {
+	if e.ContextLines == nil {
+		return DefaultContextLines
+	}
+	return *e.ContextLines
 }
 
 // generateRawDiff generates a human-readable diff output
@@ -432,11 +528,40 @@ func (e *Engine) generateRawDiff(result *DiffResult) string {
 			resourceDiff.APIVersion,
 			resourceDiff.Namespace))
 		sb.WriteString(fmt.Sprintf("Change Type: %s\n", resourceDiff.ChangeType))
+		if previous := resourceDiff.PreviousIdentity; previous != nil {
+			sb.WriteString(fmt.Sprintf("Previously: %s/%s (%s/%s), %.0f%% similar, recreated by Kubernetes\n",
+				previous.Kind, previous.Name, previous.APIVersion, previous.Namespace, resourceDiff.Similarity*100))
+		}
+		if source := resourceDiff.Source; source != nil {
+			sb.WriteString(fmt.Sprintf("Source: %s\n", source.Template))
+		}
+		if hook := resourceDiff.Hook; hook != nil {
+			sb.WriteString(fmt.Sprintf("Hook: %s (weight %d", strings.Join(hook.Events, ","), hook.Weight))
+			if len(hook.DeletePolicies) > 0 {
+				sb.WriteString(fmt.Sprintf(", delete policy %s", strings.Join(hook.DeletePolicies, ",")))
+			}
+			sb.WriteString(")\n")
+		}
+
+		if resourceDiff.Object != nil {
+			e.writeRawObject(&sb, resourceDiff)
+		}
 
 		if len(resourceDiff.Fields) > 0 {
 			sb.WriteString("Fields Changed:\n")
-			for _, field := range resourceDiff.Fields {
-				sb.WriteString(fmt.Sprintf("  %s [%s]\n", field.Path, field.Type))
+			for i, field := range resourceDiff.Fields {
+				sb.WriteString(fmt.Sprintf("  %s [%s]", field.Path, field.Type))
+				if delta := resourceDiff.Changes[i].QuantityDelta; delta != nil {
+					sb.WriteString(fmt.Sprintf(" (%s)", delta.Delta))
+				}
+				if image := resourceDiff.Changes[i].Image; image != nil {
+					sb.WriteString(fmt.Sprintf(" (%s)", image.summary()))
+				}
+				sb.WriteString("\n")
+				if hunks := resourceDiff.Changes[i].Hunks; len(hunks) > 0 {
+					writeHunks(&sb, "    ", hunks)
+					continue
+				}
 				if field.Type == ChangeTypeRemoved {
 					sb.WriteString(fmt.Sprintf("    - %v\n", e.formatValue(field.OldValue)))
 				} else if field.Type == ChangeTypeAdded {
@@ -453,6 +578,23 @@ func (e *Engine) generateRawDiff(result *DiffResult) string {
 	return sb.String()
 }
 
+// writeRawObject writes the object of an added or removed resource as YAML lines
+// prefixed with + or -
+func (e *Engine) writeRawObject(sb *strings.Builder, resourceDiff ResourceDiff) {
+	data, err := yaml.Marshal(resourceDiff.Object)
+	if err != nil {
+		return
+	}
+	prefix := "+"
+	if resourceDiff.ChangeType == ChangeTypeRemoved {
+		prefix = "-"
+	}
+	sb.WriteString("Object:\n")
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		sb.WriteString(fmt.Sprintf("  %s %s\n", prefix, line))
+	}
+}
+
 // formatValue formats a value for display
 func (e *Engine) formatValue(value interface{}) string {
 	if value == nil {
@@ -0,0 +1,236 @@
+package diff
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// quantityParentsByKind are the fields whose values are maps of resource
+// quantities in a given kind, e.g. ResourceQuota spec.hard and LimitRange
+// limits[].max. Elsewhere these names are ordinary fields.
+var quantityParentsByKind = map[string]map[string]bool{
+	"ResourceQuota": {
+		"hard": true,
+		"used": true,
+	},
+	"LimitRange": {
+		"default":              true,
+		"defaultRequest":       true,
+		"max":                  true,
+		"min":                  true,
+		"maxLimitRequestRatio": true,
+	},
+	"PersistentVolume": {
+		"capacity": true,
+	},
+	"PersistentVolumeClaim": {
+		"capacity": true,
+	},
+}
+
+// quantityFields are fields that hold a single resource quantity
+var quantityFields = map[string]bool{
+	"sizeLimit": true,
+}
+
+// intOrStringFields are fields of type IntOrString, where 8080 and "8080" are the same value
+var intOrStringFields = map[string]bool{
+	"port":           true,
+	"targetPort":     true,
+	"maxSurge":       true,
+	"maxUnavailable": true,
+	"minAvailable":   true,
+}
+
+// durationFields are fields known to hold Go duration strings, such as the
+// reconcile interval of a Flux resource or the scrape timeout of a ServiceMonitor.
+// Any other string that happens to parse as a duration is compared literally.
+var durationFields = map[string]bool{
+	"interval":      true,
+	"retryInterval": true,
+	"timeout":       true,
+	"scrapeTimeout": true,
+	"duration":      true,
+	"renewBefore":   true,
+}
+
+// deepEqual compares two values found at path for equality. Resource quantities,
+// IntOrString fields, durations and image references are compared by the value they
+// denote, so that e.g. 500m and 0.5 CPU, 1Gi and 1024Mi, 8080 and "8080", or 1h and
+// 60m are equal.
+func (e *Engine) deepEqual(path fieldPath, v1, v2 interface{}) bool {
+	switch a := v1.(type) {
+	case map[string]interface{}:
+		b, ok := v2.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, exists := b[key]
+			if !exists || !e.deepEqual(path.child(key), value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := v2.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !e.deepEqual(path.index(i), a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if reflect.DeepEqual(v1, v2) {
+		return true
+	}
+	return equivalentScalars(path, v1, v2)
+}
+
+// equivalentScalars reports whether two different scalars at path denote the same value
+func equivalentScalars(path fieldPath, v1, v2 interface{}) bool {
+	tokens := path.tokens
+
+	// ConfigMap or Secret data, metadata, labels and annotations are opaque to
+	// Kubernetes, and container env, args and command are passed to the
+	// application as written; all of them are only compared literally
+	if isDataPath(tokens) {
+		return false
+	}
+	if hasField(tokens, "metadata", "labels", "annotations", "env", "args", "command") {
+		return false
+	}
+
+	// Image references name the same image however they are spelled, e.g. nginx
+	// and docker.io/library/nginx
+	if lastField(tokens) == "image" {
+		image1, ok1 := v1.(string)
+		image2, ok2 := v2.(string)
+		return ok1 && ok2 && ParseImageReference(image1) == ParseImageReference(image2)
+	}
+
+	if isQuantityPath(path) {
+		q1, ok1 := parseQuantity(v1)
+		q2, ok2 := parseQuantity(v2)
+		return ok1 && ok2 && q1.Cmp(q2) == 0
+	}
+
+	if intOrStringFields[lastField(tokens)] {
+		n1, ok1 := intOrStringValue(v1)
+		n2, ok2 := intOrStringValue(v2)
+		return ok1 && ok2 && n1 == n2
+	}
+
+	if durationFields[lastField(tokens)] {
+		d1, ok1 := parseDuration(v1)
+		d2, ok2 := parseDuration(v2)
+		return ok1 && ok2 && d1 == d2
+	}
+
+	return false
+}
+
+// quantityDelta returns the difference between two resource quantities at path,
+// or nil if path does not hold quantities or either value is not one
+func quantityDelta(path fieldPath, before, after interface{}) *QuantityDelta {
+	if !isQuantityPath(path) {
+		return nil
+	}
+	q1, ok1 := parseQuantity(before)
+	q2, ok2 := parseQuantity(after)
+	if !ok1 || !ok2 {
+		return nil
+	}
+
+	delta := q2.DeepCopy()
+	delta.Sub(q1)
+	formatted := delta.String()
+	if delta.Sign() > 0 {
+		formatted = "+" + formatted
+	}
+	return &QuantityDelta{Delta: formatted, Value: delta.AsApproximateFloat64()}
+}
+
+// isQuantityPath reports whether the field at path holds a resource quantity.
+// Resource names may contain dots, e.g. nvidia.com/gpu.
+func isQuantityPath(path fieldPath) bool {
+	tokens := path.tokens
+	if quantityFields[lastField(tokens)] {
+		return true
+	}
+	if len(tokens) < 2 {
+		return false
+	}
+
+	parent := lastField(tokens[:len(tokens)-1])
+	if quantityParentsByKind[path.kind][parent] {
+		return true
+	}
+	switch parent {
+	case "limits", "requests":
+		// Resource requirements of containers and of persistent volume claims
+		return len(tokens) >= 3 && lastField(tokens[:len(tokens)-2]) == "resources"
+	case "overhead":
+		// Pod overhead
+		return len(tokens) >= 3 && lastField(tokens[:len(tokens)-2]) == "spec"
+	}
+	return false
+}
+
+// parseQuantity parses a resource quantity written as a string or a number
+func parseQuantity(value interface{}) (resource.Quantity, bool) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		s = strconv.Itoa(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	default:
+		return resource.Quantity{}, false
+	}
+	q, err := resource.ParseQuantity(s)
+	return q, err == nil
+}
+
+// intOrStringValue returns the integer an IntOrString value holds, written as a
+// number or a numeric string. Percentages and names are not integers.
+func intOrStringValue(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, false
+		}
+		return int64(v), true
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	}
+	return 0, false
+}
+
+// parseDuration parses a Go duration string such as "90s" or "1h30m".
+// Bare numbers are not durations.
+func parseDuration(value interface{}) (time.Duration, bool) {
+	s, ok := value.(string)
+	if !ok || strings.Trim(s, "0123456789.+-") == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(s)
+	return d, err == nil
+}
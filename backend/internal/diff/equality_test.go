@@ -0,0 +1,159 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const quantityManifest = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  annotations:
+    timeout: %s
+spec:
+  strategy:
+    rollingUpdate:
+      maxSurge: %s
+  template:
+    spec:
+      containers:
+        - name: app
+          resources:
+            requests:
+              cpu: %s
+              memory: %s
+          livenessProbe:
+            httpGet:
+              port: %s
+            timeout: %s
+`
+
+func quantityDeployment(timeoutAnnotation, maxSurge, cpu, memory, port, timeout string) string {
+	return fmt.Sprintf(quantityManifest, timeoutAnnotation, maxSurge, cpu, memory, port, timeout)
+}
+
+func TestEngineCompare_EquivalentValues(t *testing.T) {
+	before := quantityDeployment(`"1h"`, "1", "500m", "1Gi", "8080", "1h")
+	after := quantityDeployment(`"1h"`, `"1"`, "0.5", "1024Mi", `"8080"`, "60m")
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	assert.Empty(t, result.Resources, "semantically equal values must not be reported")
+}
+
+func TestEngineCompare_LiteralValuesStillDiffer(t *testing.T) {
+	before := quantityDeployment(`"1h"`, "1", "500m", "1Gi", "8080", "1h")
+	after := quantityDeployment(`"60m"`, `"50%"`, "500m", "1Gi", "http", "1h")
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	assert.NotNil(t, findChange(changes, "metadata.annotations.timeout"), "annotations are compared literally")
+	assert.NotNil(t, findChange(changes, "spec.strategy.rollingUpdate.maxSurge"), "a percentage is not an integer")
+	assert.NotNil(t, findChange(changes, "spec.template.spec.containers[name=app].livenessProbe.httpGet.port"), "a named port is not a number")
+}
+
+func TestEngineCompare_QuantityDelta(t *testing.T) {
+	before := quantityDeployment(`"1h"`, "1", "500m", "1Gi", "8080", "1h")
+	after := quantityDeployment(`"1h"`, "1", "0.75", "512Mi", "8080", "1h")
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	cpu := findChange(result.Resources[0].Changes, "spec.template.spec.containers[name=app].resources.requests.cpu")
+	require.NotNil(t, cpu)
+	require.NotNil(t, cpu.QuantityDelta)
+	assert.Equal(t, "+250m", cpu.QuantityDelta.Delta)
+	assert.InDelta(t, 0.25, cpu.QuantityDelta.Value, 1e-9)
+
+	memory := findChange(result.Resources[0].Changes, "spec.template.spec.containers[name=app].resources.requests.memory")
+	require.NotNil(t, memory)
+	require.NotNil(t, memory.QuantityDelta)
+	assert.Equal(t, "-512Mi", memory.QuantityDelta.Delta)
+	assert.Equal(t, float64(-512*1024*1024), memory.QuantityDelta.Value)
+
+	assert.Contains(t, result.Raw, "resources.requests.cpu [modified] (+250m)")
+}
+
+// pathOf builds the path of the field given by tokens in a resource of the given kind
+func pathOf(kind string, tokens ...PathToken) fieldPath {
+	path := resourcePath(kind)
+	for _, token := range tokens {
+		switch t := token.(type) {
+		case int:
+			path = path.index(t)
+		case string:
+			path = path.child(t)
+		}
+	}
+	return path
+}
+
+func TestEquivalentScalars(t *testing.T) {
+	tests := []struct {
+		path   fieldPath
+		v1, v2 interface{}
+		equal  bool
+	}{
+		{pathOf("Pod", "spec", "containers", 0, "resources", "limits", "cpu"), "1", "1000m", true},
+		{pathOf("Pod", "spec", "containers", 0, "resources", "limits", "cpu"), float64(2), "2000m", true},
+		{pathOf("Pod", "spec", "containers", 0, "resources", "limits", "memory"), "1G", "1Gi", false},
+		{pathOf("Pod", "spec", "containers", 0, "resources", "limits", "nvidia.com/gpu"), "1", "1000m", true},
+		{pathOf("Pod", "spec", "overhead", "cpu"), "250m", "0.25", true},
+		{pathOf("ResourceQuota", "spec", "hard", "requests.storage"), "10Gi", "10240Mi", true},
+		{pathOf("LimitRange", "spec", "limits", 0, "max", "memory"), "1Gi", "1024Mi", true},
+		{pathOf("PersistentVolume", "spec", "capacity", "storage"), "1Gi", "1024Mi", true},
+		{pathOf("PersistentVolumeClaim", "spec", "resources", "requests", "storage"), "1Gi", "1024Mi", true},
+		{pathOf("HelmRelease", "spec", "values", "max", "size"), "1", "1000m", false},
+		{pathOf("HelmRelease", "spec", "values", "hard", "limit"), "1k", "1000", false},
+		{pathOf("Pod", "spec", "volumes", 0, "emptyDir", "sizeLimit"), "1Gi", "1024Mi", true},
+		{pathOf("Service", "spec", "ports", 0, "targetPort"), float64(8080), "8080", true},
+		{pathOf("PodDisruptionBudget", "spec", "minAvailable"), "50%", float64(50), false},
+		{pathOf("Kustomization", "spec", "interval"), "5m", "300s", true},
+		{pathOf("Kustomization", "spec", "interval"), "5m", "5", false},
+		{pathOf("Kustomization", "spec", "suspendFor"), "5m", "300s", false},
+		{pathOf("Pod", "spec", "containers", 0, "env", 0, "value"), "60s", "1m", false},
+		{pathOf("Pod", "spec", "containers", 0, "args", 0), "60s", "1m", false},
+		{pathOf("Deployment", "spec", "template", "metadata", "annotations", "timeout"), "60s", "1m", false},
+		{pathOf("Secret", "data", "interval"), "5m", "300s", false},
+		{pathOf("Deployment", "spec", "replicas"), float64(1), "1", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.equal, equivalentScalars(tt.path, tt.v1, tt.v2), "%s: %v vs %v", JSONPointer(tt.path.tokens), tt.v1, tt.v2)
+	}
+}
+
+func TestEngineCompare_ApplicationValuesComparedLiterally(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      annotations:
+        timeout: %[1]s
+    spec:
+      containers:
+        - name: app
+          args: ["--timeout", "%[1]s"]
+          env:
+            - name: TIMEOUT
+              value: "%[1]s"
+`
+
+	result, err := NewEngine().Compare(fmt.Sprintf(manifest, "60s"), fmt.Sprintf(manifest, "1m"))
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+	assert.Equal(t, 3, result.Stats.Changes.Total)
+}
@@ -0,0 +1,42 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Helm hook annotations
+const (
+	HookAnnotation             = "helm.sh/hook"
+	HookWeightAnnotation       = "helm.sh/hook-weight"
+	HookDeletePolicyAnnotation = "helm.sh/hook-delete-policy"
+)
+
+// hookInfo returns the hook configuration of a resource, or nil if it is not a Helm hook.
+// Test resources are hooks on the "test" event.
+func hookInfo(r Resource) *HookInfo {
+	events := splitAnnotationList(r.Metadata.Annotations[HookAnnotation])
+	if len(events) == 0 {
+		return nil
+	}
+
+	// Helm treats a missing or malformed weight as 0
+	weight, _ := strconv.Atoi(strings.TrimSpace(r.Metadata.Annotations[HookWeightAnnotation]))
+
+	return &HookInfo{
+		Events:         events,
+		Weight:         weight,
+		DeletePolicies: splitAnnotationList(r.Metadata.Annotations[HookDeletePolicyAnnotation]),
+	}
+}
+
+// splitAnnotationList splits a comma-separated annotation value, dropping empty entries
+func splitAnnotationList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
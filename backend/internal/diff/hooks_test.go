@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineCompare_HookInfo(t *testing.T) {
+	engine := NewEngine()
+
+	manifest1 := `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: pre-install,pre-upgrade
+    helm.sh/hook-weight: "-5"
+    helm.sh/hook-delete-policy: before-hook-creation
+spec:
+  backoffLimit: 1
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: smoke-test
+  annotations:
+    helm.sh/hook: test
+spec:
+  restartPolicy: Never
+`
+
+	manifest2 := `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: pre-install,pre-upgrade
+    helm.sh/hook-weight: "-5"
+    helm.sh/hook-delete-policy: before-hook-creation
+spec:
+  backoffLimit: 3
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: plain
+data:
+  key: value
+`
+
+	result, err := engine.Compare(manifest1, manifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 3)
+
+	byName := make(map[string]ResourceDiff)
+	for _, r := range result.Resources {
+		byName[r.Identity.Name] = r
+	}
+
+	migrate := byName["migrate"]
+	require.NotNil(t, migrate.Hook)
+	assert.Equal(t, []string{"pre-install", "pre-upgrade"}, migrate.Hook.Events)
+	assert.Equal(t, -5, migrate.Hook.Weight)
+	assert.Equal(t, []string{"before-hook-creation"}, migrate.Hook.DeletePolicies)
+
+	// Removed resources keep the hook configuration they had
+	smokeTest := byName["smoke-test"]
+	assert.Equal(t, ChangeTypeRemoved, smokeTest.ChangeType)
+	require.NotNil(t, smokeTest.Hook)
+	assert.Equal(t, []string{"test"}, smokeTest.Hook.Events)
+	assert.Equal(t, 0, smokeTest.Hook.Weight)
+
+	assert.Nil(t, byName["plain"].Hook)
+	assert.Contains(t, result.Raw, "Hook: pre-install,pre-upgrade (weight -5, delete policy before-hook-creation)")
+}
@@ -0,0 +1,107 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Identity conflict types
+const (
+	IdentityConflictDuplicate  = "duplicate"           // Same apiVersion, kind, namespace and name rendered more than once
+	IdentityConflictAPIVersion = "apiVersion-conflict" // Same kind, namespace and name under different apiVersions
+)
+
+// objectIdentity identifies a Kubernetes object regardless of the apiVersion it is served at
+type objectIdentity struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// FindIdentityConflicts reports resources of one manifest that would collide in the
+// cluster: exact duplicates, and the same kind, namespace and name under different
+// apiVersions, e.g. extensions/v1beta1 and networking.k8s.io/v1 Ingresses. The
+// diff only keeps the last of the colliding resources, while Helm rejects them.
+func FindIdentityConflicts(resources []Resource) []IdentityConflict {
+	groups := make(map[objectIdentity][]Resource)
+	for _, r := range resources {
+		id := objectIdentity{kind: r.Kind, namespace: r.Metadata.Namespace, name: r.Metadata.Name}
+		groups[id] = append(groups[id], r)
+	}
+
+	ids := make([]objectIdentity, 0, len(groups))
+	for id, group := range groups {
+		if len(group) > 1 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].kind != ids[j].kind {
+			return ids[i].kind < ids[j].kind
+		}
+		if ids[i].namespace != ids[j].namespace {
+			return ids[i].namespace < ids[j].namespace
+		}
+		return ids[i].name < ids[j].name
+	})
+
+	var conflicts []IdentityConflict
+	for _, id := range ids {
+		group := groups[id]
+		conflict := IdentityConflict{
+			Type:      IdentityConflictDuplicate,
+			Kind:      id.kind,
+			Name:      id.name,
+			Namespace: id.namespace,
+			Count:     len(group),
+		}
+
+		seen := make(map[string]bool)
+		for _, r := range group {
+			if !seen[r.APIVersion] {
+				seen[r.APIVersion] = true
+				conflict.APIVersions = append(conflict.APIVersions, r.APIVersion)
+			}
+			if r.Source != nil {
+				conflict.Sources = append(conflict.Sources, r.Source.Template)
+			}
+		}
+		sort.Strings(conflict.APIVersions)
+
+		name := id.name
+		if id.namespace != "" {
+			name = id.namespace + "/" + id.name
+		}
+		if len(conflict.APIVersions) > 1 {
+			conflict.Type = IdentityConflictAPIVersion
+			conflict.Message = fmt.Sprintf("%s %s is rendered under %d apiVersions: %s",
+				id.kind, name, len(conflict.APIVersions), strings.Join(conflict.APIVersions, ", "))
+		} else {
+			conflict.Message = fmt.Sprintf("%s %s is rendered %d times", id.kind, name, len(group))
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+// addIdentityConflicts records the identity conflicts of one side and appends them to the raw output
+func (r *DiffResult) addIdentityConflicts(side string, conflicts []IdentityConflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Identity Conflicts (%s) ===\n", side))
+	for _, c := range conflicts {
+		c.Side = side
+		r.IdentityConflicts = append(r.IdentityConflicts, c)
+		sb.WriteString(fmt.Sprintf("  [%s] %s\n", c.Type, c.Message))
+		for _, source := range c.Sources {
+			sb.WriteString(fmt.Sprintf("    from %s\n", source))
+		}
+	}
+	sb.WriteString("\n")
+
+	r.Raw += sb.String()
+}
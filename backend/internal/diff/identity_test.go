@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindIdentityConflicts(t *testing.T) {
+	manifest := `# Source: app/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: first
+---
+# Source: app/templates/extra.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: second
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: web
+  namespace: prod
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+  namespace: prod
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: other
+`
+	resources, err := ParseManifests(manifest)
+	require.NoError(t, err)
+
+	conflicts := FindIdentityConflicts(resources)
+	require.Len(t, conflicts, 2)
+
+	assert.Equal(t, IdentityConflict{
+		Type:        IdentityConflictDuplicate,
+		Kind:        "ConfigMap",
+		Name:        "config",
+		APIVersions: []string{"v1"},
+		Count:       2,
+		Sources:     []string{"app/templates/configmap.yaml", "app/templates/extra.yaml"},
+		Message:     "ConfigMap config is rendered 2 times",
+	}, conflicts[0])
+
+	assert.Equal(t, IdentityConflictAPIVersion, conflicts[1].Type)
+	assert.Equal(t, []string{"extensions/v1beta1", "networking.k8s.io/v1"}, conflicts[1].APIVersions)
+	assert.Equal(t, "Ingress prod/web is rendered under 2 apiVersions: extensions/v1beta1, networking.k8s.io/v1", conflicts[1].Message)
+}
+
+func TestCompare_IdentityConflicts(t *testing.T) {
+	before := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\ndata:\n  k: v\n"
+	after := before + "---\n" + before
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.IdentityConflicts, 1)
+	assert.Equal(t, "right", result.IdentityConflicts[0].Side)
+	assert.Contains(t, result.Raw, "=== Identity Conflicts (right) ===")
+
+	result, err = NewEngine().Compare(before, before)
+	require.NoError(t, err)
+	assert.Empty(t, result.IdentityConflicts)
+}
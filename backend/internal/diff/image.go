@@ -0,0 +1,119 @@
+package diff
+
+import (
+	"strings"
+)
+
+// defaultRegistry is the registry of image references without a registry host
+const defaultRegistry = "docker.io"
+
+// Image change kinds
+const (
+	ImageChangeRegistry       = "registry"        // Same image pulled from another registry
+	ImageChangeRepository     = "repository"      // Another image
+	ImageChangeTag            = "tag"             // Tag changed, see VersionBump
+	ImageChangeDigest         = "digest"          // Pinned digest changed
+	ImageChangeDigestPinned   = "digest-pinned"   // Digest added
+	ImageChangeDigestUnpinned = "digest-unpinned" // Digest removed
+)
+
+// ParseImageReference splits a container image reference such as
+// "ghcr.io/org/app:1.2.3@sha256:..." into its parts. Docker Hub references are
+// normalized the way the container runtime resolves them, so "nginx" is
+// docker.io/library/nginx. A missing tag is left empty rather than set to "latest".
+func ParseImageReference(image string) ImageReference {
+	var ref ImageReference
+	name := strings.TrimSpace(image)
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Digest = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+	}
+
+	ref.Registry = defaultRegistry
+	if first, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry, name = first, rest
+	}
+	if ref.Registry == "index.docker.io" {
+		ref.Registry = defaultRegistry
+	}
+	if ref.Registry == defaultRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	ref.Repository = name
+	return ref
+}
+
+// classifyImageChange describes the change between two image references, or
+// returns nil if they are the same image
+func classifyImageChange(before, after string) *ImageChange {
+	ref1, ref2 := ParseImageReference(before), ParseImageReference(after)
+	change := &ImageChange{Before: ref1, After: ref2}
+
+	if ref1.Registry != ref2.Registry {
+		change.Kinds = append(change.Kinds, ImageChangeRegistry)
+	}
+	if ref1.Repository != ref2.Repository {
+		change.Kinds = append(change.Kinds, ImageChangeRepository)
+	}
+	if ref1.Tag != ref2.Tag {
+		change.Kinds = append(change.Kinds, ImageChangeTag)
+		change.VersionBump, change.Downgrade = ClassifyVersionChange(ref1.Tag, ref2.Tag)
+	}
+	switch {
+	case ref1.Digest == ref2.Digest:
+	case ref1.Digest == "":
+		change.Kinds = append(change.Kinds, ImageChangeDigestPinned)
+	case ref2.Digest == "":
+		change.Kinds = append(change.Kinds, ImageChangeDigestUnpinned)
+	default:
+		change.Kinds = append(change.Kinds, ImageChangeDigest)
+	}
+
+	if len(change.Kinds) == 0 {
+		return nil
+	}
+	return change
+}
+
+// importance rates an image change: another image, a major or unknown tag change
+// and a downgrade are high; a minor bump, a registry move and a changed or removed
+// digest are medium; a patch or prerelease bump and pinning a digest are low
+func (c *ImageChange) importance() string {
+	importance := "low"
+	for _, kind := range c.Kinds {
+		switch kind {
+		case ImageChangeRepository:
+			return "high"
+		case ImageChangeTag:
+			switch {
+			case c.Downgrade, c.VersionBump == VersionBumpMajor, c.VersionBump == "":
+				return "high"
+			case c.VersionBump == VersionBumpMinor:
+				importance = "medium"
+			}
+		case ImageChangeRegistry, ImageChangeDigest, ImageChangeDigestUnpinned:
+			importance = "medium"
+		}
+	}
+	return importance
+}
+
+// summary describes an image change in a few words for the raw output,
+// e.g. "tag minor bump" or "registry, digest-pinned"
+func (c *ImageChange) summary() string {
+	parts := make([]string, len(c.Kinds))
+	for i, kind := range c.Kinds {
+		parts[i] = kind
+		if kind == ImageChangeTag && c.VersionBump != "" {
+			parts[i] += " " + c.VersionBump
+			if c.Downgrade {
+				parts[i] += " downgrade"
+			} else {
+				parts[i] += " bump"
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
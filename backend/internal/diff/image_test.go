@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImageReference(t *testing.T) {
+	tests := map[string]ImageReference{
+		"nginx":                           {Registry: "docker.io", Repository: "library/nginx"},
+		"nginx:1.25":                      {Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+		"bitnami/redis:7.2.4":             {Registry: "docker.io", Repository: "bitnami/redis", Tag: "7.2.4"},
+		"index.docker.io/library/nginx":   {Registry: "docker.io", Repository: "library/nginx"},
+		"ghcr.io/org/team/app:v1.2.3":     {Registry: "ghcr.io", Repository: "org/team/app", Tag: "v1.2.3"},
+		"localhost:5000/app":              {Registry: "localhost:5000", Repository: "app"},
+		"localhost/app:dev":               {Registry: "localhost", Repository: "app", Tag: "dev"},
+		"quay.io/app@sha256:abc":          {Registry: "quay.io", Repository: "app", Digest: "sha256:abc"},
+		"quay.io/app:1.0.0@sha256:abc":    {Registry: "quay.io", Repository: "app", Tag: "1.0.0", Digest: "sha256:abc"},
+		"registry:5000/team/app:2.0":      {Registry: "registry:5000", Repository: "team/app", Tag: "2.0"},
+		"my-registry.example.com/app:1.0": {Registry: "my-registry.example.com", Repository: "app", Tag: "1.0"},
+	}
+
+	for image, expected := range tests {
+		assert.Equal(t, expected, ParseImageReference(image), image)
+	}
+}
+
+func TestClassifyImageChange(t *testing.T) {
+	tests := []struct {
+		before, after string
+		kinds         []string
+		bump          string
+		downgrade     bool
+		importance    string
+	}{
+		{"nginx:1.25.3", "nginx:1.25.4", []string{ImageChangeTag}, VersionBumpPatch, false, "low"},
+		{"nginx:1.24.0", "nginx:1.25.0", []string{ImageChangeTag}, VersionBumpMinor, false, "medium"},
+		{"nginx:1.25.0", "nginx:2.0.0", []string{ImageChangeTag}, VersionBumpMajor, false, "high"},
+		{"nginx:1.25.1", "nginx:1.25.0", []string{ImageChangeTag}, VersionBumpPatch, true, "high"},
+		{"app:v1.2.3", "app:v1.2.4", []string{ImageChangeTag}, VersionBumpPatch, false, "low"},
+		{"nginx:stable", "nginx:mainline", []string{ImageChangeTag}, "", false, "high"},
+		{"nginx:1.25", "ghcr.io/mirror/nginx:1.25", []string{ImageChangeRegistry, ImageChangeRepository}, "", false, "high"},
+		{"docker.io/bitnami/redis:7.2.4", "registry.example.com/bitnami/redis:7.2.4", []string{ImageChangeRegistry}, "", false, "medium"},
+		{"nginx:1.25", "redis:1.25", []string{ImageChangeRepository}, "", false, "high"},
+		{"nginx:1.25", "nginx:1.25@sha256:abc", []string{ImageChangeDigestPinned}, "", false, "low"},
+		{"nginx:1.25@sha256:abc", "nginx:1.25", []string{ImageChangeDigestUnpinned}, "", false, "medium"},
+		{"nginx@sha256:abc", "nginx@sha256:def", []string{ImageChangeDigest}, "", false, "medium"},
+	}
+
+	for _, tt := range tests {
+		name := fmt.Sprintf("%s -> %s", tt.before, tt.after)
+		change := classifyImageChange(tt.before, tt.after)
+		require.NotNil(t, change, name)
+		assert.Equal(t, tt.kinds, change.Kinds, name)
+		assert.Equal(t, tt.bump, change.VersionBump, name)
+		assert.Equal(t, tt.downgrade, change.Downgrade, name)
+		assert.Equal(t, tt.importance, change.importance(), name)
+	}
+
+	assert.Nil(t, classifyImageChange("nginx", "docker.io/library/nginx"))
+}
+
+func TestEngineCompare_ImageChange(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: %s
+`
+
+	result, err := NewEngine().Compare(fmt.Sprintf(manifest, "nginx:1.25.3"), fmt.Sprintf(manifest, "nginx:1.25.4"))
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	change := findChange(result.Resources[0].Changes, "spec.template.spec.containers[name=app].image")
+	require.NotNil(t, change)
+	require.NotNil(t, change.Image)
+	assert.Equal(t, "1.25.3", change.Image.Before.Tag)
+	assert.Equal(t, "1.25.4", change.Image.After.Tag)
+	assert.Equal(t, "low", change.Importance)
+	assert.Contains(t, change.Flags, "rollout-trigger")
+	assert.Contains(t, result.Raw, "containers[name=app].image [modified] (tag patch bump)")
+
+	// Spelling out the implied registry is not a change
+	result, err = NewEngine().Compare(fmt.Sprintf(manifest, "nginx:1.25.3"), fmt.Sprintf(manifest, "docker.io/library/nginx:1.25.3"))
+	require.NoError(t, err)
+	assert.Empty(t, result.Resources)
+}
@@ -0,0 +1,251 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// listMergeKeys maps a list field name to the candidate merge keys used to
+// match its elements across versions. Candidates are tried in order; the first
+// one that uniquely identifies every element on both sides is used.
+// Composite keys mirror the Kubernetes strategic merge keys (e.g. ports are
+// matched by containerPort+protocol).
+var listMergeKeys = map[string][][]string{
+	"containers":                {{"name"}},
+	"initContainers":            {{"name"}},
+	"ephemeralContainers":       {{"name"}},
+	"env":                       {{"name"}},
+	"ports":                     {{"containerPort", "protocol"}, {"port", "protocol"}},
+	"volumes":                   {{"name"}},
+	"volumeMounts":              {{"mountPath"}},
+	"volumeDevices":             {{"devicePath"}},
+	"volumeClaimTemplates":      {{"metadata.name"}},
+	"imagePullSecrets":          {{"name"}},
+	"tolerations":               {{"key", "operator", "effect"}},
+	"hostAliases":               {{"ip"}},
+	"topologySpreadConstraints": {{"topologyKey", "whenUnsatisfiable"}},
+	"subjects":                  {{"kind", "namespace", "name"}},
+	"webhooks":                  {{"name"}},
+}
+
+// mergeKeyDefaults provides the API default for merge key fields that may be
+// omitted, so that an explicit default and an omitted field match
+var mergeKeyDefaults = map[string]string{
+	"protocol": "TCP",
+}
+
+const (
+	// ArrayStrategyKeyed matches list elements by their merge key
+	ArrayStrategyKeyed = "keyed"
+	// ArrayStrategyIndexed matches list elements by position
+	ArrayStrategyIndexed = "indexed"
+)
+
+// listElement is a list item together with its position and identity
+type listElement struct {
+	index int
+	id    string
+	value interface{}
+}
+
+// compareLists compares two lists found under the given field name.
+// Lists with a known merge key are matched element by element using that key;
+// all other lists are compared by index. Matched elements are recursed into,
+// so a single env var change yields a single leaf change.
+func (e *Engine) compareLists(path fieldPath, field string, list1, list2 []interface{}) []Change {
+	if key, ids1, ids2, ok := resolveMergeKey(field, list1, list2); ok {
+		return e.compareKeyedLists(path, key, list1, list2, ids1, ids2)
+	}
+	return e.compareIndexedLists(path, list1, list2)
+}
+
+// compareKeyedLists compares two lists whose elements are identified by a merge key
+func (e *Engine) compareKeyedLists(path fieldPath, key []string, list1, list2 []interface{}, ids1, ids2 []string) []Change {
+	before := make(map[string]listElement, len(list1))
+	for i, item := range list1 {
+		before[ids1[i]] = listElement{index: i, id: ids1[i], value: item}
+	}
+	after := make(map[string]listElement, len(list2))
+	for i, item := range list2 {
+		after[ids2[i]] = listElement{index: i, id: ids2[i], value: item}
+	}
+
+	arrayDiff := &ArrayDiff{
+		Strategy: ArrayStrategyKeyed,
+		Key:      strings.Join(key, "+"),
+	}
+
+	// Walk removed elements in their original order, then the new list in order
+	var removed []listElement
+	for i := range list1 {
+		if _, ok := after[ids1[i]]; !ok {
+			removed = append(removed, before[ids1[i]])
+			arrayDiff.Removed = append(arrayDiff.Removed, ids1[i])
+		}
+	}
+
+	// Elements are located by their merge key value, so a path names the same
+	// element whichever side it is found on; the tokens hold the element's index
+	// in the list it is found in, the old list for a removed element
+	var elementChanges []Change
+	var changes []Change
+	for i, item := range list2 {
+		elemPath := path.element(i, mergeKeySelector(key, item))
+		prev, ok := before[ids2[i]]
+		if !ok {
+			arrayDiff.Added = append(arrayDiff.Added, ids2[i])
+			elementChanges = append(elementChanges, e.createChange(OpAdd, elemPath, nil, item))
+			continue
+		}
+		if e.deepEqual(elemPath, prev.value, item) {
+			continue
+		}
+		arrayDiff.Modified = append(arrayDiff.Modified, ids2[i])
+		changes = append(changes, e.compareValues(elemPath, "", prev.value, item)...)
+	}
+
+	for _, elem := range removed {
+		elemPath := path.element(elem.index, mergeKeySelector(key, elem.value))
+		elementChanges = append(elementChanges, e.createChange(OpRemove, elemPath, elem.value, nil))
+	}
+
+	return attachArrayDiff(append(elementChanges, changes...), arrayDiff)
+}
+
+// compareIndexedLists compares two lists position by position
+func (e *Engine) compareIndexedLists(path fieldPath, list1, list2 []interface{}) []Change {
+	arrayDiff := &ArrayDiff{Strategy: ArrayStrategyIndexed}
+
+	var elementChanges []Change
+	var changes []Change
+	for i := 0; i < len(list1) || i < len(list2); i++ {
+		elemPath := path.index(i)
+		switch {
+		case i >= len(list1):
+			arrayDiff.Added = append(arrayDiff.Added, i)
+			elementChanges = append(elementChanges, e.createChange(OpAdd, elemPath, nil, list2[i]))
+		case i >= len(list2):
+			arrayDiff.Removed = append(arrayDiff.Removed, i)
+			elementChanges = append(elementChanges, e.createChange(OpRemove, elemPath, list1[i], nil))
+		case !e.deepEqual(elemPath, list1[i], list2[i]):
+			arrayDiff.Modified = append(arrayDiff.Modified, i)
+			if isContainer(list1[i]) && isContainer(list2[i]) {
+				changes = append(changes, e.compareValues(elemPath, "", list1[i], list2[i])...)
+			} else {
+				elementChanges = append(elementChanges, e.createChange(OpReplace, elemPath, list1[i], list2[i]))
+			}
+		}
+	}
+
+	return attachArrayDiff(append(elementChanges, changes...), arrayDiff)
+}
+
+// attachArrayDiff sets the list-level ArrayDiff on the changes of a list's
+// elements, added, removed and modified alike. Changes inside a nested list keep
+// the ArrayDiff of that list.
+func attachArrayDiff(changes []Change, arrayDiff *ArrayDiff) []Change {
+	for i := range changes {
+		if changes[i].ArrayDiff == nil {
+			changes[i].ArrayDiff = arrayDiff
+		}
+	}
+	return changes
+}
+
+// isContainer reports whether a value is a map or a list that can be recursed into
+func isContainer(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// resolveMergeKey picks the merge key for a list field and computes the
+// identity of every element on both sides. It returns ok=false if the field
+// has no known merge key or no candidate uniquely identifies the elements.
+func resolveMergeKey(field string, list1, list2 []interface{}) ([]string, []string, []string, bool) {
+	for _, candidate := range listMergeKeys[field] {
+		ids1, ok1 := elementIdentities(candidate, list1)
+		ids2, ok2 := elementIdentities(candidate, list2)
+		if ok1 && ok2 {
+			return candidate, ids1, ids2, true
+		}
+	}
+	return nil, nil, nil, false
+}
+
+// elementIdentities computes the merge key identity of each list element.
+// Every element must be an object carrying the first key field, and the
+// resulting identities must be unique.
+func elementIdentities(key []string, list []interface{}) ([]string, bool) {
+	ids := make([]string, len(list))
+	seen := make(map[string]bool, len(list))
+
+	for i, item := range list {
+		parts, ok := mergeKeyValues(key, item)
+		if !ok {
+			return nil, false
+		}
+
+		id := strings.Join(parts, "/")
+		if seen[id] {
+			return nil, false
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+
+	return ids, true
+}
+
+// mergeKeyValues returns the value of each merge key field of a list element.
+// The element must be an object carrying the first key field; other fields
+// default to their API default.
+func mergeKeyValues(key []string, item interface{}) ([]string, bool) {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	parts := make([]string, len(key))
+	for j, field := range key {
+		value, found := lookupField(m, field)
+		if !found {
+			if j == 0 {
+				return nil, false
+			}
+			value = mergeKeyDefaults[field]
+		}
+		parts[j] = fmt.Sprintf("%v", value)
+	}
+	return parts, true
+}
+
+// mergeKeySelector formats the merge key of a list element for its display path,
+// e.g. "name=app" or "containerPort=8080,protocol=TCP"
+func mergeKeySelector(key []string, item interface{}) string {
+	parts, _ := mergeKeyValues(key, item)
+	selector := make([]string, len(key))
+	for j, field := range key {
+		selector[j] = field + "=" + parts[j]
+	}
+	return strings.Join(selector, ",")
+}
+
+// lookupField resolves a dot-separated field inside a map
+func lookupField(m map[string]interface{}, field string) (interface{}, bool) {
+	var current interface{} = m
+	for _, part := range strings.Split(field, ".") {
+		cm, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = cm[part]
+		if !ok || current == nil {
+			return nil, false
+		}
+	}
+	return current, true
+}
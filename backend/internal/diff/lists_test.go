@@ -0,0 +1,334 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findChange(changes []Change, path string) *Change {
+	for i := range changes {
+		if changes[i].Path == path {
+			return &changes[i]
+		}
+	}
+	return nil
+}
+
+func TestCompareLists_EnvVarAdded(t *testing.T) {
+	engine := NewEngine()
+
+	manifest1 := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: api:v1
+        env:
+        - name: LOG_LEVEL
+          value: info
+`
+
+	manifest2 := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: api:v1
+        env:
+        - name: FEATURE_X
+          value: "true"
+        - name: LOG_LEVEL
+          value: info
+`
+
+	result, err := engine.Compare(manifest1, manifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	require.Len(t, changes, 1, "only the new env var should be reported")
+
+	change := changes[0]
+	assert.Equal(t, OpAdd, change.Op)
+	assert.Equal(t, "spec.template.spec.containers[name=app].env[name=FEATURE_X]", change.Path)
+	assert.Equal(t, "container.env", change.SemanticType)
+	assert.Equal(t, map[string]interface{}{"name": "FEATURE_X", "value": "true"}, change.After)
+
+	require.NotNil(t, change.ArrayDiff)
+	assert.Equal(t, ArrayStrategyKeyed, change.ArrayDiff.Strategy)
+	assert.Equal(t, "name", change.ArrayDiff.Key)
+	assert.Equal(t, []interface{}{"FEATURE_X"}, change.ArrayDiff.Added)
+}
+
+func TestCompareLists_ContainersMatchedByName(t *testing.T) {
+	engine := NewEngine()
+
+	manifest1 := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: api:v1
+      - name: sidecar
+        image: proxy:v1
+`
+
+	// Containers reordered and sidecar image bumped
+	manifest2 := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      containers:
+      - name: sidecar
+        image: proxy:v2
+      - name: app
+        image: api:v1
+`
+
+	result, err := engine.Compare(manifest1, manifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	require.Len(t, changes, 1)
+	assert.Equal(t, OpReplace, changes[0].Op)
+	assert.Equal(t, "spec.template.spec.containers[name=sidecar].image", changes[0].Path)
+	assert.Equal(t, []PathToken{"spec", "template", "spec", "containers", 0, "image"}, changes[0].PathTokens)
+	assert.Equal(t, "proxy:v1", changes[0].Before)
+	assert.Equal(t, "proxy:v2", changes[0].After)
+	assert.Equal(t, "container.image", changes[0].SemanticType)
+}
+
+func TestCompareLists_ContainerRemoved(t *testing.T) {
+	engine := NewEngine()
+
+	manifest1 := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  initContainers:
+  - name: migrate
+    image: tools:v1
+  - name: wait
+    image: busybox
+  containers:
+  - name: app
+    image: web:v1
+`
+
+	manifest2 := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  initContainers:
+  - name: wait
+    image: busybox
+  containers:
+  - name: app
+    image: web:v1
+`
+
+	result, err := engine.Compare(manifest1, manifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	require.Len(t, changes, 1)
+	assert.Equal(t, OpRemove, changes[0].Op)
+	assert.Equal(t, "spec.initContainers[name=migrate]", changes[0].Path)
+	require.NotNil(t, changes[0].ArrayDiff)
+	assert.Equal(t, []interface{}{"migrate"}, changes[0].ArrayDiff.Removed)
+}
+
+func TestCompareLists_PortsCompositeKey(t *testing.T) {
+	engine := NewEngine()
+
+	// Explicit TCP protocol must match an omitted protocol
+	manifest1 := `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+    targetPort: 8080
+  - port: 53
+    protocol: UDP
+    targetPort: 5353
+`
+
+	manifest2 := `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 53
+    protocol: UDP
+    targetPort: 5353
+  - port: 80
+    protocol: TCP
+    targetPort: 9090
+`
+
+	result, err := engine.Compare(manifest1, manifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	assert.Nil(t, findChange(changes, "spec.ports[port=80,protocol=TCP].protocol"), "the TCP default is normalized away")
+
+	targetPort := findChange(changes, "spec.ports[port=80,protocol=TCP].targetPort")
+	require.NotNil(t, targetPort)
+	assert.Equal(t, float64(8080), targetPort.Before)
+	assert.Equal(t, float64(9090), targetPort.After)
+	assert.Len(t, changes, 1)
+}
+
+func TestCompareLists_IndexedFallback(t *testing.T) {
+	engine := NewEngine()
+
+	manifest1 := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: job
+spec:
+  containers:
+  - name: app
+    image: job:v1
+    args:
+    - --verbose
+    - --port=8080
+`
+
+	manifest2 := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: job
+spec:
+  containers:
+  - name: app
+    image: job:v1
+    args:
+    - --verbose
+    - --port=9090
+    - --debug
+`
+
+	result, err := engine.Compare(manifest1, manifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	require.Len(t, changes, 2)
+
+	replaced := findChange(changes, "spec.containers[name=app].args.1")
+	require.NotNil(t, replaced)
+	assert.Equal(t, OpReplace, replaced.Op)
+	require.NotNil(t, replaced.ArrayDiff)
+	assert.Equal(t, ArrayStrategyIndexed, replaced.ArrayDiff.Strategy)
+	assert.Equal(t, []interface{}{2}, replaced.ArrayDiff.Added)
+	assert.Equal(t, []interface{}{1}, replaced.ArrayDiff.Modified)
+
+	added := findChange(changes, "spec.containers[name=app].args.2")
+	require.NotNil(t, added)
+	assert.Equal(t, OpAdd, added.Op)
+	assert.Equal(t, "--debug", added.After)
+}
+
+func TestCompareLists_DuplicateKeysFallBackToIndexed(t *testing.T) {
+	list1 := []interface{}{
+		map[string]interface{}{"name": "A", "value": "1"},
+		map[string]interface{}{"name": "A", "value": "2"},
+	}
+	list2 := []interface{}{
+		map[string]interface{}{"name": "A", "value": "1"},
+		map[string]interface{}{"name": "A", "value": "3"},
+	}
+
+	_, _, _, ok := resolveMergeKey("env", list1, list2)
+	assert.False(t, ok)
+
+	changes := NewEngine().compareLists(resourcePath("Pod").child("env"), "env", list1, list2)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "env.1.value", changes[0].Path)
+}
+
+func TestCompareLists_KeyedPathsNameOneElement(t *testing.T) {
+	manifest1 := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: a
+    image: a:v1
+  - name: b
+    image: b:v1
+`
+
+	// a is removed, which moves b to index 0, and b's image changes
+	manifest2 := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: b
+    image: b:v2
+`
+
+	result, err := NewEngine().Compare(manifest1, manifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	require.Len(t, changes, 2)
+
+	removed := findChange(changes, "spec.containers[name=a]")
+	require.NotNil(t, removed)
+	assert.Equal(t, OpRemove, removed.Op)
+	assert.Equal(t, "/spec/containers/0", removed.Pointer, "a removed element is located in the old list")
+
+	image := findChange(changes, "spec.containers[name=b].image")
+	require.NotNil(t, image)
+	assert.Equal(t, "/spec/containers/0/image", image.Pointer, "a modified element is located in the new list")
+
+	// Both changes carry the list's ArrayDiff, including the modified element's
+	for _, change := range changes {
+		require.NotNil(t, change.ArrayDiff, change.Path)
+		assert.Equal(t, []interface{}{"a"}, change.ArrayDiff.Removed)
+		assert.Equal(t, []interface{}{"b"}, change.ArrayDiff.Modified)
+	}
+}
@@ -1,7 +1,9 @@
 package diff
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 
@@ -10,47 +12,195 @@ import (
 )
 
 // ParseManifests parses a YAML string containing multiple resources
-// Returns a list of normalized resources
+// Returns a list of normalized resources; documents that cannot be used are logged and skipped
 func ParseManifests(yamlContent string) ([]Resource, error) {
-	if strings.TrimSpace(yamlContent) == "" {
-		return []Resource{}, nil
+	resources, warnings, err := ParseManifestStream(strings.NewReader(yamlContent))
+	for _, w := range warnings {
+		log.Warnf("Skipping manifest document %d (line %d): %s", w.Document, w.Line, w.Message)
 	}
+	return resources, err
+}
 
-	// Split by YAML document separator
-	docs := strings.Split(yamlContent, "\n---")
+// ParseManifestStream parses a multi-document YAML stream one document at a time.
+// Documents are separated by "---" lines, which may carry a comment or content, and
+// ended by "..." lines; "kind: List" documents are expanded into their items.
+// Documents that are invalid or not Kubernetes objects are skipped with a warning.
+// An error is only returned if the stream cannot be read.
+func ParseManifestStream(r io.Reader) ([]Resource, []ParseWarning, error) {
 	resources := make([]Resource, 0)
+	var warnings []ParseWarning
 
-	for _, doc := range docs {
-		doc = strings.TrimSpace(doc)
-		if doc == "" {
-			continue
+	document := 0
+	parse := func(doc manifestDocument) {
+		if !doc.hasContent {
+			return
+		}
+		document++
+		parsed, err := parseDocument(doc.text.String())
+		if err != nil {
+			warnings = append(warnings, ParseWarning{Document: document, Line: doc.line, Message: err.Error()})
 		}
+		resources = append(resources, parsed...)
+	}
 
-		// Parse the YAML document
-		var raw map[string]interface{}
-		if err := yaml.Unmarshal([]byte(doc), &raw); err != nil {
-			// Log warning for invalid YAML to aid debugging
-			log.Warnf("Skipping invalid YAML document: %v", err)
-			continue
+	reader := bufio.NewReader(r)
+	doc := manifestDocument{line: 1}
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		trimmed := strings.TrimRight(line, "\r\n")
+
+		switch {
+		case isDocumentMarker(trimmed, "---"):
+			// Directives and comments before the marker belong to the new document
+			if doc.hasContent {
+				parse(doc)
+				doc = manifestDocument{line: lineNum}
+			}
+			doc.text.WriteString(line)
+			doc.hasContent = doc.hasContent || hasInlineContent(trimmed[3:])
+		case isDocumentMarker(trimmed, "..."):
+			parse(doc)
+			doc = manifestDocument{line: lineNum + 1}
+		default:
+			doc.text.WriteString(line)
+			if t := strings.TrimSpace(trimmed); t != "" && !strings.HasPrefix(t, "#") && !strings.HasPrefix(trimmed, "%") {
+				doc.hasContent = true
+			}
 		}
 
-		// Skip empty documents
-		if len(raw) == 0 {
-			continue
+		if err == io.EOF {
+			break
 		}
+	}
+	parse(doc)
 
-		// Extract resource
-		resource, err := parseResource(raw)
-		if err != nil {
-			// Log warning when resource cannot be parsed
-			log.Warnf("Skipping unparseable resource: %v", err)
-			continue
+	return resources, warnings, nil
+}
+
+// addParseWarnings records the parse warnings of one side and appends them to the raw output
+func (r *DiffResult) addParseWarnings(side string, warnings []ParseWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Parse Warnings (%s) ===\n", side))
+	for _, w := range warnings {
+		w.Side = side
+		r.ParseWarnings = append(r.ParseWarnings, w)
+		sb.WriteString(fmt.Sprintf("  document %d (line %d): %s\n", w.Document, w.Line, w.Message))
+	}
+	sb.WriteString("\n")
+
+	r.Raw += sb.String()
+}
+
+// manifestDocument accumulates the lines of one document of a YAML stream
+type manifestDocument struct {
+	text       strings.Builder
+	line       int  // Line the document starts at
+	hasContent bool // Whether it has more than directives, comments and markers
+}
+
+// isDocumentMarker reports whether a line is a "---" or "..." marker, optionally
+// followed by whitespace and more content
+func isDocumentMarker(line, marker string) bool {
+	if !strings.HasPrefix(line, marker) {
+		return false
+	}
+	rest := line[len(marker):]
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t'
+}
+
+// hasInlineContent reports whether the text following a "---" marker is content
+// rather than a comment
+func hasInlineContent(rest string) bool {
+	rest = strings.TrimSpace(rest)
+	return rest != "" && !strings.HasPrefix(rest, "#")
+}
+
+// parseDocument parses a single YAML document into resources, expanding List kinds.
+// Resources that could be parsed are returned along with an error describing the rest.
+func parseDocument(doc string) ([]Resource, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal([]byte(doc), &raw); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %v", err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("document is not a mapping")
+	}
+	if len(obj) == 0 {
+		return nil, nil
+	}
+
+	source := parseSourceComment(doc)
+
+	if items, ok := listItems(obj); ok {
+		resources := make([]Resource, 0, len(items))
+		var problems []string
+		for i, item := range items {
+			itemObj, ok := item.(map[string]interface{})
+			if !ok {
+				problems = append(problems, fmt.Sprintf("item %d is not a mapping", i))
+				continue
+			}
+			resource, err := parseResource(itemObj)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("item %d: %v", i, err))
+				continue
+			}
+			resource.Source = source
+			resources = append(resources, resource)
 		}
+		if len(problems) > 0 {
+			return resources, fmt.Errorf("list %s", strings.Join(problems, "; "))
+		}
+		return resources, nil
+	}
+
+	resource, err := parseResource(obj)
+	if err != nil {
+		return nil, err
+	}
+	resource.Source = source
+	return []Resource{resource}, nil
+}
 
-		resources = append(resources, resource)
+// listItems returns the items of a List document, like `kubectl get -o yaml` output.
+// Besides kind List, only built-in kinds such as PodList are lists; a custom
+// resource whose kind ends in List is an ordinary resource.
+func listItems(obj map[string]interface{}) ([]interface{}, bool) {
+	kind, _ := obj["kind"].(string)
+	apiVersion, _ := obj["apiVersion"].(string)
+	if kind != "List" && !(strings.HasSuffix(kind, "List") && isBuiltinAPIVersion(apiVersion)) {
+		return nil, false
+	}
+	if kind == "List" && obj["items"] == nil {
+		return nil, true
 	}
+	items, ok := obj["items"].([]interface{})
+	return items, ok
+}
 
-	return resources, nil
+// isBuiltinAPIVersion reports whether an apiVersion belongs to a Kubernetes API
+// group: the core group ("v1"), a group without a domain ("apps/v1") or a
+// *.k8s.io group ("networking.k8s.io/v1"). Custom resources use a domain of their own.
+func isBuiltinAPIVersion(apiVersion string) bool {
+	group, _, found := strings.Cut(apiVersion, "/")
+	if !found {
+		return apiVersion != ""
+	}
+	return !strings.Contains(group, ".") || strings.HasSuffix(group, ".k8s.io")
 }
 
 // parseResource converts a raw map to a normalized Resource
@@ -99,6 +249,41 @@ func parseResource(raw map[string]interface{}) (Resource, error) {
 	return resource, nil
 }
 
+// resourceObject reassembles a parsed resource into a Kubernetes object
+func resourceObject(r Resource) map[string]interface{} {
+	metadata := make(map[string]interface{}, len(r.Metadata.Other)+4)
+	for key, value := range r.Metadata.Other {
+		metadata[key] = value
+	}
+	if r.Metadata.Name != "" {
+		metadata["name"] = r.Metadata.Name
+	}
+	if r.Metadata.Namespace != "" {
+		metadata["namespace"] = r.Metadata.Namespace
+	}
+	if len(r.Metadata.Labels) > 0 {
+		metadata["labels"] = r.Metadata.Labels
+	}
+	if len(r.Metadata.Annotations) > 0 {
+		metadata["annotations"] = r.Metadata.Annotations
+	}
+
+	obj := make(map[string]interface{}, len(r.Other)+5)
+	for key, value := range r.Other {
+		obj[key] = value
+	}
+	obj["apiVersion"] = r.APIVersion
+	obj["kind"] = r.Kind
+	obj["metadata"] = metadata
+	if r.Spec != nil {
+		obj["spec"] = r.Spec
+	}
+	if r.Data != nil {
+		obj["data"] = r.Data
+	}
+	return obj
+}
+
 // parseMetadata extracts and normalizes metadata
 func parseMetadata(metadata map[string]interface{}) Metadata {
 	m := Metadata{
@@ -178,7 +363,8 @@ func GetResourceKey(resource Resource) ResourceKey {
 	}
 }
 
-// GetResourcesByKey creates a map of resources by their keys
+// GetResourcesByKey creates a map of resources by their keys; of duplicate keys the last
+// resource is kept, see FindIdentityConflicts
 func GetResourcesByKey(resources []Resource) map[ResourceKey]Resource {
 	result := make(map[ResourceKey]Resource)
 	for _, resource := range resources {
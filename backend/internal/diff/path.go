@@ -0,0 +1,113 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// fieldPath locates a value while recursing into a resource. The display path and
+// the tokens are built together, so field names holding dots or slashes, such as
+// the annotation key app.kubernetes.io/name, stay single tokens.
+type fieldPath struct {
+	kind    string      // Kind of the resource the path belongs to
+	display string      // Dot-notation path, e.g. "spec.template.spec.containers.0.image"
+	tokens  []PathToken // Field names (string) and list indexes (int)
+
+	// Number of tokens locating the string an embedded document was parsed from,
+	// or 0 outside embedded documents
+	docStart int
+}
+
+// resourcePath returns the path of the root of a resource of the given kind
+func resourcePath(kind string) fieldPath {
+	return fieldPath{kind: kind, tokens: []PathToken{}}
+}
+
+// child returns the path of a map key below p. Dots in the key are escaped in
+// the display path, e.g. "metadata.annotations.app\.kubernetes\.io/name", so that a
+// display path names exactly one field.
+func (p fieldPath) child(key string) fieldPath {
+	return p.descend(escapePathKey(key), key)
+}
+
+// index returns the path of a list element below p
+func (p fieldPath) index(i int) fieldPath {
+	return p.descend(strconv.Itoa(i), i)
+}
+
+// element returns the path of the element at index i of a keyed list below p,
+// displayed by its merge key selector, e.g. "spec.containers[name=app]"
+func (p fieldPath) element(i int, selector string) fieldPath {
+	return fieldPath{kind: p.kind, display: p.display + "[" + selector + "]", tokens: p.appendToken(i), docStart: p.docStart}
+}
+
+// document returns the root of a document embedded in the string at p. Its fields
+// are displayed after a "#", e.g. "data.application\.yaml#server.port", and
+// continue the tokens of p.
+func (p fieldPath) document() fieldPath {
+	return fieldPath{kind: p.kind, display: p.display + "#", tokens: p.tokens, docStart: len(p.tokens)}
+}
+
+// descend returns the path of a field or list index below p, displayed as segment
+func (p fieldPath) descend(segment string, token PathToken) fieldPath {
+	display := segment
+	switch {
+	case p.atDocumentRoot():
+		display = p.display + segment // Fields of an embedded document follow its "#"
+	case p.display != "":
+		display = p.display + "." + segment
+	}
+	return fieldPath{kind: p.kind, display: display, tokens: p.appendToken(token), docStart: p.docStart}
+}
+
+// atDocumentRoot reports whether p is the root of an embedded document
+func (p fieldPath) atDocumentRoot() bool {
+	return p.docStart > 0 && p.docStart == len(p.tokens)
+}
+
+// pointers returns the RFC 6901 JSON Pointer of p into its resource and, inside an
+// embedded document, the pointer into that document. The resource pointer of a
+// field in an embedded document points at the string holding the document, e.g.
+// "/data/application.yaml" with the embedded pointer "/server/port".
+func (p fieldPath) pointers() (pointer, embedded string) {
+	if p.docStart == 0 {
+		return JSONPointer(p.tokens), ""
+	}
+	return JSONPointer(p.tokens[:p.docStart]), JSONPointer(p.tokens[p.docStart:])
+}
+
+// appendToken returns a copy of the tokens of p with token appended, so that
+// sibling paths never share a backing array
+func (p fieldPath) appendToken(token PathToken) []PathToken {
+	tokens := make([]PathToken, len(p.tokens), len(p.tokens)+1)
+	copy(tokens, p.tokens)
+	return append(tokens, token)
+}
+
+// pointerEscaper escapes a reference token as RFC 6901 requires, "~" before "/"
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// JSONPointer formats path tokens as an RFC 6901 JSON Pointer, e.g.
+// "/metadata/annotations/app.kubernetes.io~1name". No tokens point at the whole document.
+func JSONPointer(tokens []PathToken) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		switch t := token.(type) {
+		case int:
+			sb.WriteString(strconv.Itoa(t))
+		case string:
+			sb.WriteString(pointerEscaper.Replace(t))
+		}
+	}
+	return sb.String()
+}
+
+// pathKeyEscaper escapes backslashes, dots and the "#" starting an embedded
+// document with a backslash
+var pathKeyEscaper = strings.NewReplacer(`\`, `\\`, ".", `\.`, "#", `\#`)
+
+// escapePathKey escapes a map key used as a dot-notation path segment
+func escapePathKey(key string) string {
+	return pathKeyEscaper.Replace(key)
+}
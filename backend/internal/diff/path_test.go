@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPointer(t *testing.T) {
+	tests := []struct {
+		tokens   []PathToken
+		expected string
+	}{
+		{[]PathToken{}, ""},
+		{[]PathToken{"spec", "replicas"}, "/spec/replicas"},
+		{[]PathToken{"spec", "containers", 0, "image"}, "/spec/containers/0/image"},
+		{[]PathToken{"metadata", "annotations", "app.kubernetes.io/name"}, "/metadata/annotations/app.kubernetes.io~1name"},
+		{[]PathToken{"metadata", "labels", "a~b/c"}, "/metadata/labels/a~0b~1c"},
+		{[]PathToken{"data", ""}, "/data/"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, JSONPointer(tt.tokens))
+	}
+}
+
+func TestFieldPath_SiblingsDoNotShareTokens(t *testing.T) {
+	parent := resourcePath("Deployment").child("spec").child("template").child("spec")
+	first := parent.child("containers")
+	second := parent.child("volumes")
+
+	assert.Equal(t, []PathToken{"spec", "template", "spec", "containers"}, first.tokens)
+	assert.Equal(t, []PathToken{"spec", "template", "spec", "volumes"}, second.tokens)
+	assert.Equal(t, "spec.template.spec.containers", first.display)
+}
+
+func TestFieldPath_EscapesKeys(t *testing.T) {
+	labels := resourcePath("Pod").child("metadata").child("labels")
+
+	// Without escaping, both keys would display as metadata.labels.a.b
+	assert.Equal(t, `metadata.labels.a\.b`, labels.child("a.b").display)
+	assert.Equal(t, "metadata.labels.a.b", labels.child("a").child("b").display)
+	assert.Equal(t, `metadata.labels.a\\\.b`, labels.child(`a\.b`).display)
+}
+
+func TestFieldPath_EmbeddedDocument(t *testing.T) {
+	data := resourcePath("ConfigMap").child("data")
+
+	// A "#" in a key can't be mistaken for the start of an embedded document
+	server := data.child("app.yaml").document().child("server")
+	port := server.child("port")
+	assert.Equal(t, `data.app\.yaml#server.port`, port.display)
+	assert.Equal(t, `data.app\#yaml.server`, data.child("app#yaml").child("server").display)
+	assert.Equal(t, `data.app\.yaml#a/b`, data.child("app.yaml").document().child("a/b").display)
+	assert.Equal(t, `data.app\.yaml#0`, data.child("app.yaml").document().index(0).display)
+
+	pointer, embedded := port.pointers()
+	assert.Equal(t, "/data/app.yaml", pointer)
+	assert.Equal(t, "/server/port", embedded)
+	assert.Equal(t, []PathToken{"data", "app.yaml", "server", "port"}, port.tokens)
+
+	pointer, embedded = data.child("app.yaml").pointers()
+	assert.Equal(t, "/data/app.yaml", pointer)
+	assert.Empty(t, embedded)
+}
+
+func TestEngineCompare_PathsWithDotsAndSlashes(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  annotations:
+    app.kubernetes.io/name: %s
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: %s
+    spec:
+      containers:
+        - name: app
+          image: nginx:1.25.3
+          resources:
+            limits:
+              nvidia.com/gpu: %s
+`
+
+	result, err := NewEngine().Compare(
+		fmt.Sprintf(manifest, "web", "abc", "1"),
+		fmt.Sprintf(manifest, "frontend", "def", "2"),
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+	changes := result.Resources[0].Changes
+
+	name := findChange(changes, `metadata.annotations.app\.kubernetes\.io/name`)
+	require.NotNil(t, name)
+	assert.Equal(t, []PathToken{"metadata", "annotations", "app.kubernetes.io/name"}, name.PathTokens)
+	assert.Equal(t, "/metadata/annotations/app.kubernetes.io~1name", name.Pointer)
+	assert.Equal(t, "metadata.annotation", name.SemanticType)
+	assert.Equal(t, "low", name.Importance)
+
+	checksum := findChange(changes, "spec.template.metadata.annotations.checksum/config")
+	require.NotNil(t, checksum)
+	assert.Equal(t, "/spec/template/metadata/annotations/checksum~1config", checksum.Pointer)
+	assert.Equal(t, "metadata.annotation", checksum.SemanticType)
+
+	gpu := findChange(changes, `spec.template.spec.containers[name=app].resources.limits.nvidia\.com/gpu`)
+	require.NotNil(t, gpu)
+	assert.Equal(t, []PathToken{"spec", "template", "spec", "containers", 0, "resources", "limits", "nvidia.com/gpu"}, gpu.PathTokens)
+	assert.Equal(t, "resources.general", gpu.SemanticType)
+	require.NotNil(t, gpu.QuantityDelta, "extended resources are quantities too")
+	assert.Equal(t, "+1", gpu.QuantityDelta.Delta)
+}
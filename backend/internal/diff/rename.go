@@ -0,0 +1,323 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// RenameSimilarityThreshold is the minimum content similarity, between 0 and 1, for a
+// removed and an added resource of the same kind to be reported as renamed or moved
+const RenameSimilarityThreshold = 0.8
+
+// maxRenamePairs caps the removed and added resource pairs scored for renames.
+// Larger diffs report their removed and added resources as they are, so rename
+// detection can't make comparing large inputs quadratic.
+const maxRenamePairs = 10000
+
+// renameCandidate is a possible pairing of a removed and an added resource
+type renameCandidate struct {
+	removed, added int // Indexes into the result's resources
+	similarity     float64
+}
+
+// renameGroup holds the removed and added resources that may be paired: resources of
+// one kind in one namespace, which may be renamed, or with one name, which may be moved
+type renameGroup struct {
+	removed, added []int // Indexes into the result's resources
+}
+
+// detectRenames pairs removed and added resources of the same kind whose content is
+// at least RenameSimilarityThreshold similar, best matches first, and replaces each
+// pair with a single renamed or moved resource diff. Only resources in the same
+// namespace or with the same name are paired, so a resource both renamed and moved
+// stays removed and added. Kubernetes still deletes and recreates such resources,
+// so they are flagged with Recreate.
+// Detection is skipped when there are more than maxRenamePairs pairs to score.
+// Returns the number of field-level changes added to the result.
+func (e *Engine) detectRenames(result *DiffResult, left, right map[ResourceKey]Resource) int {
+	groups := make(map[objectIdentity]*renameGroup)
+	addToGroups := func(i int, removed bool) {
+		id := result.Resources[i].Identity
+		for _, key := range []objectIdentity{
+			{kind: id.Kind, namespace: id.Namespace},
+			{kind: id.Kind, name: id.Name},
+		} {
+			group := groups[key]
+			if group == nil {
+				group = &renameGroup{}
+				groups[key] = group
+			}
+			if removed {
+				group.removed = append(group.removed, i)
+			} else {
+				group.added = append(group.added, i)
+			}
+		}
+	}
+	for i, rd := range result.Resources {
+		switch rd.ChangeType {
+		case ChangeTypeRemoved:
+			addToGroups(i, true)
+		case ChangeTypeAdded:
+			addToGroups(i, false)
+		}
+	}
+
+	pairs := 0
+	for _, group := range groups {
+		pairs += len(group.removed) * len(group.added)
+	}
+	if pairs == 0 || pairs > maxRenamePairs {
+		return 0
+	}
+
+	// Flatten each resource once rather than once per pair
+	leaves := make(map[int]map[string]interface{})
+	leavesOf := func(i int) map[string]interface{} {
+		if l, ok := leaves[i]; ok {
+			return l
+		}
+		side := right
+		if result.Resources[i].ChangeType == ChangeTypeRemoved {
+			side = left
+		}
+		leaves[i] = resourceLeaves(side[resourceDiffKey(result.Resources[i])])
+		return leaves[i]
+	}
+
+	var candidates []renameCandidate
+	for _, group := range groups {
+		for _, i := range group.removed {
+			for _, j := range group.added {
+				// The same object under a new apiVersion is not a rename
+				if diffObjectIdentity(result.Resources[i]) == diffObjectIdentity(result.Resources[j]) {
+					continue
+				}
+				if similarity := leafSimilarity(leavesOf(i), leavesOf(j)); similarity >= RenameSimilarityThreshold {
+					candidates = append(candidates, renameCandidate{removed: i, added: j, similarity: similarity})
+				}
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return 0
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		ca, cb := candidates[a], candidates[b]
+		if ca.similarity != cb.similarity {
+			return ca.similarity > cb.similarity
+		}
+		if ca.removed != cb.removed {
+			return ca.removed < cb.removed
+		}
+		return ca.added < cb.added
+	})
+
+	totalChanges := 0
+	paired := make(map[int]bool)
+	dropped := make(map[int]bool) // The removed halves of the pairs
+	for _, c := range candidates {
+		if paired[c.removed] || paired[c.added] {
+			continue
+		}
+		paired[c.removed], paired[c.added] = true, true
+		dropped[c.removed] = true
+
+		previous := result.Resources[c.removed].Identity
+		key := resourceDiffKey(result.Resources[c.added])
+		before, after := left[resourceDiffKey(result.Resources[c.removed])], right[key]
+
+		changeType := ChangeTypeRenamed
+		if previous.Namespace != key.Namespace {
+			changeType = ChangeTypeMoved
+		}
+
+		changes, suppressedChanges := e.filterSuppressedChanges(e.compareResources(before, after))
+		if suppressedChanges > 0 {
+			result.Stats.Suppressed.Changes += suppressedChanges
+		}
+		e.flagSecretChanges(after, changes)
+
+		rd := e.createResourceDiff(key, before, after, changeType)
+		rd.PreviousIdentity = &previous
+		rd.Similarity = c.similarity
+		rd.Recreate = true
+		rd.Changes = changes
+		if len(changes) > 0 {
+			rd.Summary = e.calculateResourceSummary(changes)
+			rd.Fields = e.convertToFieldDiffs(changes)
+		}
+		result.Resources[c.added] = rd
+		totalChanges += len(changes)
+
+		result.Stats.Resources.Removed--
+		result.Stats.Resources.Added--
+		if changeType == ChangeTypeMoved {
+			result.Stats.Resources.Moved++
+		} else {
+			result.Stats.Resources.Renamed++
+		}
+		result.Summary.Removed-- // Legacy, which has no rename category
+		result.Summary.Added--
+		result.Summary.Modified++
+	}
+
+	dropResources(result, dropped)
+	return totalChanges
+}
+
+// pairAPIVersionChanges replaces each removed and added resource with the same kind,
+// name and namespace, which is one object served under a new apiVersion, with a
+// single modified resource diff whose first change is the apiVersion. Kubernetes
+// updates such an object in place, so it is neither renamed nor recreated.
+// Returns the number of field-level changes added to the result.
+func (e *Engine) pairAPIVersionChanges(result *DiffResult, left, right map[ResourceKey]Resource) int {
+	added := make(map[objectIdentity]int)
+	for i, rd := range result.Resources {
+		if rd.ChangeType == ChangeTypeAdded {
+			added[diffObjectIdentity(rd)] = i
+		}
+	}
+
+	totalChanges := 0
+	dropped := make(map[int]bool)
+	for i, rd := range result.Resources {
+		if rd.ChangeType != ChangeTypeRemoved {
+			continue
+		}
+		j, ok := added[diffObjectIdentity(rd)]
+		if !ok {
+			continue
+		}
+		dropped[i] = true
+
+		key := resourceDiffKey(result.Resources[j])
+		before, after := left[resourceDiffKey(rd)], right[key]
+
+		apiVersion := e.createChange(OpReplace, resourcePath(after.Kind).child("apiVersion"), before.APIVersion, after.APIVersion)
+		changes, suppressedChanges := e.filterSuppressedChanges(append([]Change{apiVersion}, e.compareResources(before, after)...))
+		if suppressedChanges > 0 {
+			result.Stats.Suppressed.Changes += suppressedChanges
+		}
+
+		result.Stats.Resources.Removed--
+		result.Stats.Resources.Added--
+		result.Summary.Removed-- // Legacy
+		result.Summary.Added--
+		if len(changes) == 0 {
+			// Every change was suppressed, so neither half is reported
+			dropped[j] = true
+			continue
+		}
+		e.flagSecretChanges(after, changes)
+
+		modified := e.createResourceDiff(key, before, after, ChangeTypeModified)
+		modified.Changes = changes
+		modified.Summary = e.calculateResourceSummary(changes)
+		modified.Fields = e.convertToFieldDiffs(changes)
+		result.Resources[j] = modified
+		totalChanges += len(changes)
+
+		result.Stats.Resources.Modified++
+		result.Summary.Modified++
+	}
+
+	dropResources(result, dropped)
+	return totalChanges
+}
+
+// dropResources removes the resource diffs at the dropped indexes from the result
+func dropResources(result *DiffResult, dropped map[int]bool) {
+	resources := result.Resources[:0]
+	for i, rd := range result.Resources {
+		if dropped[i] {
+			continue
+		}
+		resources = append(resources, rd)
+	}
+	result.Resources = resources
+}
+
+// diffObjectIdentity returns the identity of the object a diff describes, regardless of its apiVersion
+func diffObjectIdentity(rd ResourceDiff) objectIdentity {
+	return objectIdentity{kind: rd.Identity.Kind, namespace: rd.Identity.Namespace, name: rd.Identity.Name}
+}
+
+// resourceDiffKey returns the key of the resource a diff describes
+func resourceDiffKey(rd ResourceDiff) ResourceKey {
+	return ResourceKey{
+		APIVersion: rd.Identity.APIVersion,
+		Kind:       rd.Identity.Kind,
+		Name:       rd.Identity.Name,
+		Namespace:  rd.Identity.Namespace,
+	}
+}
+
+// resourceSimilarity returns the share of leaf fields two resources have in common,
+// ignoring their names and namespaces: 2 * equal leaves / (leaves1 + leaves2).
+// Resources without content are not similar to anything.
+func resourceSimilarity(r1, r2 Resource) float64 {
+	return leafSimilarity(resourceLeaves(r1), resourceLeaves(r2))
+}
+
+// leafSimilarity returns the share of leaves two flattened resources have in common
+func leafSimilarity(leaves1, leaves2 map[string]interface{}) float64 {
+	if len(leaves1)+len(leaves2) == 0 {
+		return 0
+	}
+
+	equal := 0
+	for path, v1 := range leaves1 {
+		if v2, ok := leaves2[path]; ok && reflect.DeepEqual(v1, v2) {
+			equal++
+		}
+	}
+	return float64(2*equal) / float64(len(leaves1)+len(leaves2))
+}
+
+// resourceLeaves flattens the content of a resource into leaf values by path
+func resourceLeaves(r Resource) map[string]interface{} {
+	leaves := make(map[string]interface{})
+	for k, v := range r.Metadata.Labels {
+		leaves["metadata.labels."+k] = v
+	}
+	for k, v := range r.Metadata.Annotations {
+		leaves["metadata.annotations."+k] = v
+	}
+	for prefix, fields := range map[string]map[string]interface{}{
+		"metadata.": r.Metadata.Other,
+		"spec.":     r.Spec,
+		"data.":     r.Data,
+		"":          r.Other,
+	} {
+		for k, v := range fields {
+			flattenLeaves(prefix+k, v, leaves)
+		}
+	}
+	return leaves
+}
+
+// flattenLeaves adds the leaves of a value to leaves; empty maps and lists are leaves
+func flattenLeaves(path string, value interface{}, leaves map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			leaves[path] = v
+			return
+		}
+		for k, child := range v {
+			flattenLeaves(path+"."+k, child, leaves)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			leaves[path] = v
+			return
+		}
+		for i, child := range v {
+			flattenLeaves(fmt.Sprintf("%s.%d", path, i), child, leaves)
+		}
+	default:
+		leaves[path] = v
+	}
+}
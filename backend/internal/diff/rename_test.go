@@ -0,0 +1,160 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const renameDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    app: web
+spec:
+  replicas: %d
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+        ports:
+        - containerPort: 80
+`
+
+func TestCompare_Renamed(t *testing.T) {
+	before := fmt.Sprintf(renameDeployment, "release-web-application", "prod", 2)
+	after := fmt.Sprintf(renameDeployment, "release-web-app", "prod", 3)
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	rd := result.Resources[0]
+	assert.Equal(t, ChangeTypeRenamed, rd.ChangeType)
+	assert.Equal(t, "release-web-app", rd.Identity.Name)
+	require.NotNil(t, rd.PreviousIdentity)
+	assert.Equal(t, "release-web-application", rd.PreviousIdentity.Name)
+	assert.True(t, rd.Recreate)
+	assert.Greater(t, rd.Similarity, RenameSimilarityThreshold)
+	assert.NotEmpty(t, rd.BeforeHash)
+	assert.NotEmpty(t, rd.AfterHash)
+
+	require.Len(t, rd.Changes, 1)
+	assert.Equal(t, "spec.replicas", rd.Changes[0].Path)
+
+	assert.Equal(t, StatsResources{Renamed: 1}, result.Stats.Resources)
+	assert.Equal(t, 1, result.Stats.Changes.Total)
+	assert.Equal(t, Summary{Modified: 1, Total: 1}, result.Summary)
+	assert.Contains(t, result.Raw, "Previously: Deployment/release-web-application")
+}
+
+func TestCompare_Moved(t *testing.T) {
+	before := fmt.Sprintf(renameDeployment, "web", "staging", 2)
+	after := fmt.Sprintf(renameDeployment, "web", "prod", 2)
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+	assert.Equal(t, ChangeTypeMoved, result.Resources[0].ChangeType)
+	assert.Equal(t, "staging", result.Resources[0].PreviousIdentity.Namespace)
+	assert.Empty(t, result.Resources[0].Changes)
+	assert.Equal(t, 1, result.Stats.Resources.Moved)
+	assert.Equal(t, Summary{Modified: 1, Total: 1}, result.Summary)
+}
+
+func TestCompare_LegacySummaryCountsRenamesAsModified(t *testing.T) {
+	configMap := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+data:
+  key: %s
+`
+	before := fmt.Sprintf(renameDeployment, "release-web-application", "prod", 2) + "---\n" +
+		fmt.Sprintf(renameDeployment, "worker", "staging", 1) + "---\n" +
+		fmt.Sprintf(configMap, "settings", "old") + "---\n" +
+		fmt.Sprintf(configMap, "obsolete", "gone")
+	after := fmt.Sprintf(renameDeployment, "release-web-app", "prod", 2) + "---\n" +
+		fmt.Sprintf(renameDeployment, "worker", "prod", 1) + "---\n" +
+		fmt.Sprintf(configMap, "settings", "new")
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+
+	stats := result.Stats.Resources
+	assert.Equal(t, StatsResources{Removed: 1, Modified: 1, Renamed: 1, Moved: 1}, stats)
+	assert.Equal(t, stats.Added, result.Summary.Added)
+	assert.Equal(t, stats.Removed, result.Summary.Removed)
+	assert.Equal(t, stats.Modified+stats.Renamed+stats.Moved, result.Summary.Modified)
+	assert.Equal(t, len(result.Resources), result.Summary.Total)
+}
+
+func TestCompare_DissimilarNotRenamed(t *testing.T) {
+	before := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\ndata:\n  x: \"1\"\n  y: \"2\"\n"
+	after := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\ndata:\n  x: \"3\"\n  z: \"4\"\n"
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 2)
+	assert.Equal(t, 1, result.Stats.Resources.Added)
+	assert.Equal(t, 1, result.Stats.Resources.Removed)
+	assert.Equal(t, 0, result.Stats.Resources.Renamed)
+}
+
+func TestCompare_APIVersionChangeIsModified(t *testing.T) {
+	before := strings.Replace(fmt.Sprintf(renameDeployment, "web", "prod", 2), "apps/v1", "apps/v1beta1", 1)
+	after := fmt.Sprintf(renameDeployment, "web", "prod", 3)
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	rd := result.Resources[0]
+	assert.Equal(t, ChangeTypeModified, rd.ChangeType)
+	assert.Equal(t, "apps/v1", rd.Identity.APIVersion)
+	assert.Nil(t, rd.PreviousIdentity)
+	assert.False(t, rd.Recreate)
+
+	require.Len(t, rd.Changes, 2)
+	assert.Equal(t, "apiVersion", rd.Changes[0].Path)
+	assert.Equal(t, "apps/v1beta1", rd.Changes[0].Before)
+	assert.Equal(t, "apps/v1", rd.Changes[0].After)
+	assert.Equal(t, "spec.replicas", rd.Changes[1].Path)
+
+	assert.Equal(t, StatsResources{Modified: 1}, result.Stats.Resources)
+	assert.Equal(t, 2, result.Stats.Changes.Total)
+	assert.Equal(t, Summary{Modified: 1, Total: 1}, result.Summary)
+}
+
+func TestCompare_TooManyRenameCandidates(t *testing.T) {
+	var before, after strings.Builder
+	for i := 0; i < 101; i++ {
+		before.WriteString(fmt.Sprintf(renameDeployment, fmt.Sprintf("old-%d", i), "prod", 2) + "---\n")
+		after.WriteString(fmt.Sprintf(renameDeployment, fmt.Sprintf("new-%d", i), "prod", 2) + "---\n")
+	}
+
+	result, err := NewEngine().Compare(before.String(), after.String())
+	require.NoError(t, err)
+	assert.Equal(t, StatsResources{Added: 101, Removed: 101}, result.Stats.Resources)
+}
+
+func TestResourceSimilarity(t *testing.T) {
+	r1 := Resource{Kind: "ConfigMap", Data: map[string]interface{}{"a": "1", "b": "2"}}
+	r2 := Resource{Kind: "ConfigMap", Data: map[string]interface{}{"a": "1", "b": "3"}}
+
+	assert.Equal(t, 1.0, resourceSimilarity(r1, r1))
+	assert.Equal(t, 0.5, resourceSimilarity(r1, r2))
+	assert.Equal(t, 0.0, resourceSimilarity(Resource{}, Resource{}))
+}
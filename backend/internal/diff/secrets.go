@@ -0,0 +1,226 @@
+package diff
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// SecretHandlingSuppress replaces Secret values with a redaction marker (default)
+	SecretHandlingSuppress = "suppress"
+	// SecretHandlingShow keeps Secret values as rendered (base64)
+	SecretHandlingShow = "show"
+	// SecretHandlingDecode base64-decodes Secret data values
+	SecretHandlingDecode = "decode"
+
+	// RedactedPrefix marks a redacted Secret value
+	RedactedPrefix = "<redacted"
+
+	// secretFlag is added to changes touching a redacted Secret value
+	secretFlag = "secret-redacted"
+)
+
+// minSecretHashKeyLength is the shortest configured secret accepted for keying
+// redaction fingerprints
+const minSecretHashKeyLength = 16
+
+// secretHashKey keys the redaction fingerprints, so that they cannot be
+// brute-forced back to the secret value from stored results. It is derived from
+// configuration with SetSecretHashKey, so that fingerprints stay comparable
+// across restarts and instances, or generated with GenerateSecretHashKey.
+var (
+	secretHashKeyMu sync.RWMutex
+	secretHashKey   []byte
+)
+
+// SetSecretHashKey derives the key of redaction fingerprints from a configured secret
+func SetSecretHashKey(secret string) error {
+	if len(secret) < minSecretHashKeyLength {
+		return fmt.Errorf("secret hash key must be at least %d characters", minSecretHashKeyLength)
+	}
+	key := sha256.Sum256([]byte(secret))
+	setSecretHashKey(key[:])
+	return nil
+}
+
+// GenerateSecretHashKey sets a random key for redaction fingerprints. Fingerprints
+// are then only stable until the process restarts.
+func GenerateSecretHashKey() error {
+	key, err := randomSecretHashKey()
+	if err != nil {
+		return err
+	}
+	setSecretHashKey(key)
+	return nil
+}
+
+func randomSecretHashKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate secret hash key: %w", err)
+	}
+	return key, nil
+}
+
+func setSecretHashKey(key []byte) {
+	secretHashKeyMu.Lock()
+	defer secretHashKeyMu.Unlock()
+	secretHashKey = key
+}
+
+// currentSecretHashKey returns the key of redaction fingerprints, generating one
+// if none was set. Returns nil if no key could be generated.
+func currentSecretHashKey() []byte {
+	secretHashKeyMu.RLock()
+	key := secretHashKey
+	secretHashKeyMu.RUnlock()
+	if key != nil {
+		return key
+	}
+
+	secretHashKeyMu.Lock()
+	defer secretHashKeyMu.Unlock()
+	if secretHashKey == nil {
+		key, err := randomSecretHashKey()
+		if err != nil {
+			return nil
+		}
+		secretHashKey = key
+	}
+	return secretHashKey
+}
+
+// IsValidSecretHandling reports whether mode is a supported secret handling mode.
+// An empty mode selects the default (suppress).
+func IsValidSecretHandling(mode string) bool {
+	switch mode {
+	case "", SecretHandlingSuppress, SecretHandlingShow, SecretHandlingDecode:
+		return true
+	default:
+		return false
+	}
+}
+
+// secretHandling returns the effective secret handling mode
+func (e *Engine) secretHandling() string {
+	if e.SecretHandling == "" {
+		return SecretHandlingSuppress
+	}
+	return e.SecretHandling
+}
+
+// isSecret reports whether a resource is a core/v1 Secret
+func isSecret(r Resource) bool {
+	return r.Kind == "Secret" && r.APIVersion == "v1"
+}
+
+// applySecretHandling rewrites Secret data and stringData values according to
+// the configured mode. It runs before diffing so that hashes, changes and the
+// raw output never see values the caller asked to hide.
+func (e *Engine) applySecretHandling(resources []Resource) {
+	mode := e.secretHandling()
+	if mode == SecretHandlingShow {
+		return
+	}
+
+	for i := range resources {
+		if !isSecret(resources[i]) {
+			continue
+		}
+
+		switch mode {
+		case SecretHandlingDecode:
+			resources[i].Data = decodeSecretValues(resources[i].Data)
+		case SecretHandlingSuppress:
+			resources[i].Data = redactSecretValues(resources[i].Data)
+			if stringData, ok := resources[i].Other["stringData"].(map[string]interface{}); ok {
+				resources[i].Other["stringData"] = redactSecretValues(stringData)
+			}
+		}
+	}
+}
+
+// redactSecretValues replaces every string value with a keyed fingerprint marker
+func redactSecretValues(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			redacted[key] = RedactValue(s)
+		} else {
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
+
+// RedactValue returns the redaction marker for a secret value.
+// Identical values produce identical markers, so unchanged secrets are not
+// reported and changed secrets are visible without exposing their content.
+func RedactValue(value string) string {
+	if IsRedacted(value) {
+		return value
+	}
+	key := currentSecretHashKey()
+	if key == nil {
+		// Without a key the value is still hidden, but changes cannot be told apart
+		return RedactedPrefix + ">"
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return fmt.Sprintf("%s:%s>", RedactedPrefix, hex.EncodeToString(mac.Sum(nil))[:12])
+}
+
+// IsRedacted reports whether a value is already a redaction marker
+func IsRedacted(value string) bool {
+	return strings.HasPrefix(value, RedactedPrefix)
+}
+
+// decodeSecretValues base64-decodes string values, leaving undecodable values untouched
+func decodeSecretValues(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+
+	decoded := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		s, ok := value.(string)
+		if !ok {
+			decoded[key] = value
+			continue
+		}
+		if raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s)); err == nil {
+			decoded[key] = string(raw)
+		} else {
+			decoded[key] = value
+		}
+	}
+	return decoded
+}
+
+// isSecretValuePath reports whether path tokens point into Secret data or stringData
+func isSecretValuePath(tokens []PathToken) bool {
+	return len(tokens) > 0 && (tokens[0] == "data" || tokens[0] == "stringData")
+}
+
+// flagSecretChanges marks changes to redacted Secret values
+func (e *Engine) flagSecretChanges(r Resource, changes []Change) {
+	if !isSecret(r) || e.secretHandling() != SecretHandlingSuppress {
+		return
+	}
+
+	for i := range changes {
+		if isSecretValuePath(changes[i].PathTokens) {
+			changes[i].Flags = append(changes[i].Flags, secretFlag)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secretManifest1 = `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+data:
+  username: YWRtaW4=
+  password: b2xkLXBhc3M=
+stringData:
+  token: old-token
+`
+
+const secretManifest2 = `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+data:
+  username: YWRtaW4=
+  password: bmV3LXBhc3M=
+stringData:
+  token: new-token
+`
+
+func TestSecretHandling_SuppressByDefault(t *testing.T) {
+	engine := NewEngine()
+
+	result, err := engine.Compare(secretManifest1, secretManifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	changes := result.Resources[0].Changes
+	require.Len(t, changes, 2, "unchanged username must not be reported")
+
+	password := findChange(changes, "data.password")
+	require.NotNil(t, password)
+	assert.True(t, strings.HasPrefix(password.Before.(string), RedactedPrefix))
+	assert.True(t, strings.HasPrefix(password.After.(string), RedactedPrefix))
+	assert.NotEqual(t, password.Before, password.After)
+	assert.Contains(t, password.Flags, "secret-redacted")
+
+	token := findChange(changes, "stringData.token")
+	require.NotNil(t, token)
+	assert.True(t, strings.HasPrefix(token.After.(string), RedactedPrefix))
+
+	assert.NotContains(t, result.Raw, "bmV3LXBhc3M=")
+	assert.NotContains(t, result.Raw, "new-token")
+	assert.Contains(t, result.Metadata.NormalizationRules, "secretHandling:suppress")
+}
+
+func TestSecretHandling_Show(t *testing.T) {
+	engine := NewEngine()
+	engine.SecretHandling = SecretHandlingShow
+
+	result, err := engine.Compare(secretManifest1, secretManifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	password := findChange(result.Resources[0].Changes, "data.password")
+	require.NotNil(t, password)
+	assert.Equal(t, "b2xkLXBhc3M=", password.Before)
+	assert.Equal(t, "bmV3LXBhc3M=", password.After)
+	assert.NotContains(t, password.Flags, "secret-redacted")
+}
+
+func TestSecretHandling_Decode(t *testing.T) {
+	engine := NewEngine()
+	engine.SecretHandling = SecretHandlingDecode
+
+	result, err := engine.Compare(secretManifest1, secretManifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	password := findChange(result.Resources[0].Changes, "data.password")
+	require.NotNil(t, password)
+	assert.Equal(t, "old-pass", password.Before)
+	assert.Equal(t, "new-pass", password.After)
+
+	token := findChange(result.Resources[0].Changes, "stringData.token")
+	require.NotNil(t, token)
+	assert.Equal(t, "new-token", token.After)
+}
+
+func TestSecretHandling_IgnoresOtherKinds(t *testing.T) {
+	engine := NewEngine()
+
+	manifest1 := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value1
+`
+	manifest2 := strings.Replace(manifest1, "value1", "value2", 1)
+
+	result, err := engine.Compare(manifest1, manifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+	assert.Equal(t, "value2", result.Resources[0].Changes[0].After)
+}
+
+func TestRedactValue(t *testing.T) {
+	assert.Equal(t, RedactValue("secret"), RedactValue("secret"))
+	assert.NotEqual(t, RedactValue("secret"), RedactValue("other"))
+	assert.NotContains(t, RedactValue("secret"), "secret")
+
+	marker := RedactValue("secret")
+	assert.Equal(t, marker, RedactValue(marker), "redaction must be idempotent")
+}
+
+func TestSetSecretHashKey(t *testing.T) {
+	previous := currentSecretHashKey()
+	t.Cleanup(func() { setSecretHashKey(previous) })
+
+	require.NoError(t, SetSecretHashKey("a-configured-secret-key"))
+	marker := RedactValue("secret")
+
+	// A restarted process with a generated key fingerprints differently...
+	require.NoError(t, GenerateSecretHashKey())
+	assert.NotEqual(t, marker, RedactValue("secret"))
+
+	// ...but one configured with the same key reproduces the stored markers
+	require.NoError(t, SetSecretHashKey("a-configured-secret-key"))
+	assert.Equal(t, marker, RedactValue("secret"))
+
+	assert.Error(t, SetSecretHashKey("too-short"))
+	assert.Equal(t, marker, RedactValue("secret"), "a rejected key leaves the current one in place")
+}
+
+func TestIsValidSecretHandling(t *testing.T) {
+	for _, mode := range []string{"", "suppress", "show", "decode"} {
+		assert.True(t, IsValidSecretHandling(mode), mode)
+	}
+	assert.False(t, IsValidSecretHandling("plain"))
+}
+
+func TestSecretHandling_IncludedObjectsAreRedacted(t *testing.T) {
+	engine := NewEngine()
+	engine.IncludeObjects = true
+
+	result, err := engine.Compare("", secretManifest2)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	obj := result.Resources[0].Object
+	require.NotNil(t, obj)
+	password := obj["data"].(map[string]interface{})["password"].(string)
+	assert.True(t, strings.HasPrefix(password, RedactedPrefix))
+	token := obj["stringData"].(map[string]interface{})["token"].(string)
+	assert.True(t, strings.HasPrefix(token, RedactedPrefix))
+
+	assert.NotContains(t, result.Raw, "bmV3LXBhc3M=")
+	assert.NotContains(t, result.Raw, "new-token")
+}
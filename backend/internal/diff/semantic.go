@@ -4,36 +4,44 @@ import (
 	"strings"
 )
 
-// classifySemanticType determines the semantic type based on the path
-func classifySemanticType(path string) string {
-	// Match common Kubernetes paths to semantic types
-	// Use word boundary checks to avoid false positives
+// classifySemanticType determines the semantic type of the field at the path given by tokens
+func classifySemanticType(tokens []PathToken) string {
+	// ConfigMap and Secret data is opaque, even when it holds a parsed document
+	// with fields named like those of a workload
+	if isDataPath(tokens) {
+		return ""
+	}
+
+	// Match common Kubernetes fields to semantic types. Tokens are compared whole,
+	// so e.g. imagePullPolicy is not an image and an annotation key ending in
+	// ".image" is an annotation.
+	inContainer := hasField(tokens, "containers", "initContainers")
 	switch {
-	case strings.HasSuffix(path, ".image"):
+	case hasKeyBelow(tokens, "metadata", "annotations"):
+		return "metadata.annotation"
+	case hasKeyBelow(tokens, "metadata", "labels"):
+		return "metadata.label"
+	case lastField(tokens) == "image":
 		return "container.image"
-	case strings.Contains(path, ".env") && (strings.Contains(path, "containers") || strings.Contains(path, "initContainers")):
+	case inContainer && hasField(tokens, "env", "envFrom"):
 		return "container.env"
-	case strings.HasSuffix(path, ".replicas"):
+	case lastField(tokens) == "replicas":
 		return "workload.replicas"
-	case strings.Contains(path, ".resources.limits.cpu") || strings.Contains(path, ".resources.requests.cpu"):
+	case hasResource(tokens, "cpu"):
 		return "resources.cpu"
-	case strings.Contains(path, ".resources.limits.memory") || strings.Contains(path, ".resources.requests.memory"):
+	case hasResource(tokens, "memory"):
 		return "resources.memory"
-	case strings.Contains(path, ".resources") && (strings.Contains(path, ".limits") || strings.Contains(path, ".requests")):
+	case hasSequence(tokens, "resources", "limits") || hasSequence(tokens, "resources", "requests"):
 		return "resources.general"
-	case strings.Contains(path, ".ports") && (strings.Contains(path, "containers") || strings.Contains(path, "service")):
+	case hasField(tokens, "ports") && (inContainer || hasField(tokens, "service")):
 		return "service.port"
-	case strings.Contains(path, ".rules") && strings.Contains(path, "ingress"):
+	case hasField(tokens, "rules") && hasField(tokens, "ingress"):
 		return "ingress.rule"
-	case strings.Contains(path, "metadata.annotations."):
-		return "metadata.annotation"
-	case strings.Contains(path, "metadata.labels."):
-		return "metadata.label"
-	case strings.Contains(path, ".volumeMounts") || strings.Contains(path, ".volumes"):
+	case hasField(tokens, "volumeMounts", "volumes"):
 		return "storage.volume"
-	case strings.Contains(path, ".securityContext"):
+	case hasField(tokens, "securityContext"):
 		return "security.context"
-	case strings.HasSuffix(path, ".serviceAccountName"):
+	case lastField(tokens) == "serviceAccountName":
 		return "security.serviceAccount"
 	default:
 		return ""
@@ -41,35 +49,26 @@ func classifySemanticType(path string) string {
 }
 
 // classifyChangeCategory determines the high-level category of a change
-func classifyChangeCategory(path string) string {
+func classifyChangeCategory(tokens []PathToken) string {
 	switch {
-	case strings.Contains(path, ".resources.limits") ||
-		strings.Contains(path, ".resources.requests"):
+	case isDataPath(tokens):
+		return "config"
+	case hasSequence(tokens, "metadata", "labels") ||
+		hasSequence(tokens, "metadata", "annotations") ||
+		hasSequence(tokens, "metadata", "name"):
+		return "metadata"
+	case hasSequence(tokens, "resources", "limits") ||
+		hasSequence(tokens, "resources", "requests"):
 		return "resources"
-	case strings.Contains(path, ".replicas") ||
-		strings.Contains(path, ".image") ||
-		strings.Contains(path, ".containers") ||
-		strings.Contains(path, ".initContainers"):
+	case hasField(tokens, "replicas", "image", "containers", "initContainers"):
 		return "workload"
-	case strings.Contains(path, ".ports") ||
-		strings.Contains(path, ".service") ||
-		strings.Contains(path, ".ingress"):
+	case hasField(tokens, "ports", "service", "ingress"):
 		return "networking"
-	case strings.Contains(path, ".securityContext") ||
-		strings.Contains(path, ".serviceAccountName") ||
-		strings.Contains(path, ".imagePullSecrets"):
+	case hasField(tokens, "securityContext", "serviceAccountName", "imagePullSecrets"):
 		return "security"
-	case strings.Contains(path, ".env") ||
-		strings.Contains(path, ".configMap") ||
-		strings.Contains(path, ".secret"):
+	case hasFieldPrefix(tokens, "env", "configMap", "secret"):
 		return "config"
-	case strings.Contains(path, "metadata.labels") ||
-		strings.Contains(path, "metadata.annotations") ||
-		strings.Contains(path, "metadata.name"):
-		return "metadata"
-	case strings.Contains(path, ".volumes") ||
-		strings.Contains(path, ".volumeMounts") ||
-		strings.Contains(path, ".persistentVolumeClaim"):
+	case hasField(tokens, "volumes", "volumeMounts", "persistentVolumeClaim"):
 		return "storage"
 	default:
 		return "unknown"
@@ -77,59 +76,156 @@ func classifyChangeCategory(path string) string {
 }
 
 // determineImportance assigns an importance level to a change
-func determineImportance(path string, semanticType string) string {
+func determineImportance(tokens []PathToken, semanticType string) string {
+	if isDataPath(tokens) {
+		return "medium"
+	}
+
+	// Minor changes
+	if hasSequence(tokens, "metadata", "labels") ||
+		hasSequence(tokens, "metadata", "annotations") {
+		return "low"
+	}
+
 	// Critical changes
-	if strings.Contains(path, ".image") ||
-		strings.Contains(path, ".replicas") ||
-		strings.Contains(path, ".securityContext") {
+	if hasField(tokens, "image", "replicas", "securityContext") {
 		return "high"
 	}
 
 	// Important changes
-	if strings.Contains(path, ".resources.limits") ||
-		strings.Contains(path, ".resources.requests") ||
-		strings.Contains(path, ".env") ||
-		strings.Contains(path, ".ports") {
+	if hasSequence(tokens, "resources", "limits") ||
+		hasSequence(tokens, "resources", "requests") ||
+		hasFieldPrefix(tokens, "env") ||
+		hasField(tokens, "ports") {
 		return "medium"
 	}
 
-	// Minor changes
-	if strings.Contains(path, "metadata.labels") ||
-		strings.Contains(path, "metadata.annotations") {
-		return "low"
-	}
-
 	return "medium"
 }
 
 // determineFlags adds semantic flags to a change
-func determineFlags(path string, semanticType string) []string {
+func determineFlags(tokens []PathToken, semanticType string) []string {
 	flags := []string{}
 
-	if strings.Contains(path, ".image") {
+	// Labels, annotations and data are free-form; their keys are not fields
+	if isDataPath(tokens) || hasSequence(tokens, "metadata", "labels") || hasSequence(tokens, "metadata", "annotations") {
+		return flags
+	}
+
+	if hasField(tokens, "image") {
 		flags = append(flags, "runtime-impact", "rollout-trigger")
 	}
 
-	if strings.Contains(path, ".replicas") {
+	if hasField(tokens, "replicas") {
 		flags = append(flags, "scaling-change", "runtime-impact")
 	}
 
-	if strings.Contains(path, ".resources.limits") ||
-		strings.Contains(path, ".resources.requests") {
+	if hasSequence(tokens, "resources", "limits") ||
+		hasSequence(tokens, "resources", "requests") {
 		flags = append(flags, "runtime-impact")
 	}
 
-	if strings.Contains(path, ".securityContext") {
+	if hasField(tokens, "securityContext") {
 		flags = append(flags, "security-impact", "breaking-change")
 	}
 
-	if strings.Contains(path, ".ports") {
+	if hasField(tokens, "ports") {
 		flags = append(flags, "networking-change")
 	}
 
 	return flags
 }
 
+// isDataPath reports whether the tokens lead into ConfigMap or Secret data
+func isDataPath(tokens []PathToken) bool {
+	if len(tokens) < 2 {
+		return false
+	}
+	switch tokens[0] {
+	case "data", "stringData", "binaryData":
+		return true
+	}
+	return false
+}
+
+// lastField returns the field name the tokens end in, or "" if they end in a list index
+func lastField(tokens []PathToken) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	field, _ := tokens[len(tokens)-1].(string)
+	return field
+}
+
+// hasField reports whether any of the tokens is one of the given field names
+func hasField(tokens []PathToken, names ...string) bool {
+	for _, token := range tokens {
+		field, ok := token.(string)
+		if !ok {
+			continue
+		}
+		for _, name := range names {
+			if field == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasFieldPrefix reports whether any of the tokens starts with one of the given
+// prefixes, e.g. "env" for env and envFrom
+func hasFieldPrefix(tokens []PathToken, prefixes ...string) bool {
+	for _, token := range tokens {
+		field, ok := token.(string)
+		if !ok {
+			continue
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(field, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sequenceIndex returns the index of the first run of tokens equal to fields, or -1
+func sequenceIndex(tokens []PathToken, fields ...string) int {
+	for i := 0; i+len(fields) <= len(tokens); i++ {
+		match := true
+		for j, field := range fields {
+			if tokens[i+j] != PathToken(field) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasSequence reports whether the tokens contain fields in a row, e.g. resources, limits
+func hasSequence(tokens []PathToken, fields ...string) bool {
+	return sequenceIndex(tokens, fields...) >= 0
+}
+
+// hasKeyBelow reports whether the tokens continue past fields in a row, e.g. an
+// annotation key below metadata, annotations
+func hasKeyBelow(tokens []PathToken, fields ...string) bool {
+	i := sequenceIndex(tokens, fields...)
+	return i >= 0 && i+len(fields) < len(tokens)
+}
+
+// hasResource reports whether the tokens name a resource's request or limit,
+// e.g. resources, limits, cpu
+func hasResource(tokens []PathToken, name string) bool {
+	return hasSequence(tokens, "resources", "limits", name) ||
+		hasSequence(tokens, "resources", "requests", name)
+}
+
 // getValueType determines the JSON type of a value
 func getValueType(value interface{}) string {
 	if value == nil {
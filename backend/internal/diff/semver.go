@@ -0,0 +1,38 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Version bump classifications
+const (
+	VersionBumpMajor      = "major"
+	VersionBumpMinor      = "minor"
+	VersionBumpPatch      = "patch"
+	VersionBumpPrerelease = "prerelease" // Only the prerelease or build metadata changed
+)
+
+// ClassifyVersionChange classifies the change between two semantic versions.
+// A leading "v" is accepted. Returns "" if either version is not semver or they are
+// equal; downgrade reports whether after is lower than before.
+func ClassifyVersionChange(before, after string) (bump string, downgrade bool) {
+	v1, err1 := semver.NewVersion(strings.TrimSpace(before))
+	v2, err2 := semver.NewVersion(strings.TrimSpace(after))
+	if err1 != nil || err2 != nil || v1.Equal(v2) && v1.Metadata() == v2.Metadata() {
+		return "", false
+	}
+
+	switch {
+	case v1.Major() != v2.Major():
+		bump = VersionBumpMajor
+	case v1.Minor() != v2.Minor():
+		bump = VersionBumpMinor
+	case v1.Patch() != v2.Patch():
+		bump = VersionBumpPatch
+	default:
+		bump = VersionBumpPrerelease
+	}
+	return bump, v2.LessThan(v1)
+}
@@ -0,0 +1,30 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyVersionChange(t *testing.T) {
+	tests := []struct {
+		before, after string
+		bump          string
+		downgrade     bool
+	}{
+		{"1.2.3", "2.0.0", VersionBumpMajor, false},
+		{"v1.2.3", "v1.3.0", VersionBumpMinor, false},
+		{"1.2.3", "1.2.4", VersionBumpPatch, false},
+		{"1.2.3-rc.1", "1.2.3", VersionBumpPrerelease, false},
+		{"1.4.0", "1.2.9", VersionBumpMinor, true},
+		{"1.2.3", "1.2.3", "", false},
+		{"latest", "1.2.3", "", false},
+		{"", "1.2.3", "", false},
+	}
+
+	for _, tt := range tests {
+		bump, downgrade := ClassifyVersionChange(tt.before, tt.after)
+		assert.Equal(t, tt.bump, bump, "%s -> %s", tt.before, tt.after)
+		assert.Equal(t, tt.downgrade, downgrade, "%s -> %s", tt.before, tt.after)
+	}
+}
@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+)
+
+// sourceCommentPrefix marks the template a document was rendered from in Helm output
+const sourceCommentPrefix = "# Source: "
+
+// parseSourceComment reads the "# Source:" comment heading a rendered document.
+// Returns nil if the document has none, e.g. for kustomize output.
+func parseSourceComment(doc string) *ResourceSource {
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "---" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			return nil
+		}
+		if strings.HasPrefix(line, sourceCommentPrefix) {
+			return NewResourceSource(strings.TrimSpace(strings.TrimPrefix(line, sourceCommentPrefix)))
+		}
+	}
+	return nil
+}
+
+// NewResourceSource attributes a template path, such as
+// "umbrella/charts/redis/templates/master.yaml", to the chart that rendered it
+func NewResourceSource(template string) *ResourceSource {
+	if template == "" {
+		return nil
+	}
+
+	parts := strings.Split(template, "/")
+	charts := []string{parts[0]}
+	for i := 1; i+2 < len(parts) && parts[i] == "charts"; i += 2 {
+		charts = append(charts, parts[i+1])
+	}
+
+	return &ResourceSource{
+		Template: template,
+		Chart:    charts[len(charts)-1],
+		Subchart: strings.Join(charts[1:], "/"),
+	}
+}
+
+// SubchartGroup is the changed resources rendered by one chart of an umbrella chart
+type SubchartGroup struct {
+	Subchart  string // Empty for the parent chart and resources without a source
+	Chart     string
+	Resources []ResourceDiff
+}
+
+// GroupBySubchart groups resource diffs by the subchart that rendered them,
+// the parent chart first and subcharts in path order
+func GroupBySubchart(resources []ResourceDiff) []SubchartGroup {
+	index := make(map[string]int)
+	var groups []SubchartGroup
+	for _, rd := range resources {
+		var subchart, chart string
+		if rd.Source != nil {
+			subchart, chart = rd.Source.Subchart, rd.Source.Chart
+		}
+		i, ok := index[subchart]
+		if !ok {
+			i = len(groups)
+			index[subchart] = i
+			groups = append(groups, SubchartGroup{Subchart: subchart, Chart: chart})
+		}
+		if groups[i].Chart == "" {
+			groups[i].Chart = chart
+		}
+		groups[i].Resources = append(groups[i].Resources, rd)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Subchart < groups[j].Subchart })
+	return groups
+}
+
+// subchartStats counts changed resources and changes per subchart.
+// Returns nil unless resources carry sources, i.e. were rendered by Helm.
+func subchartStats(resources []ResourceDiff) []SubchartStats {
+	hasSource := false
+	for _, rd := range resources {
+		if rd.Source != nil {
+			hasSource = true
+			break
+		}
+	}
+	if !hasSource {
+		return nil
+	}
+
+	var stats []SubchartStats
+	for _, group := range GroupBySubchart(resources) {
+		s := SubchartStats{Subchart: group.Subchart, Chart: group.Chart}
+		for _, rd := range group.Resources {
+			switch rd.ChangeType {
+			case ChangeTypeAdded:
+				s.Resources.Added++
+			case ChangeTypeRemoved:
+				s.Resources.Removed++
+			case ChangeTypeModified:
+				s.Resources.Modified++
+			case ChangeTypeRenamed:
+				s.Resources.Renamed++
+			case ChangeTypeMoved:
+				s.Resources.Moved++
+			}
+			s.Changes.Total += len(rd.Changes)
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
@@ -0,0 +1,123 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResourceSource(t *testing.T) {
+	tests := []struct {
+		template string
+		expected *ResourceSource
+	}{
+		{"", nil},
+		{"app/templates/deployment.yaml", &ResourceSource{Template: "app/templates/deployment.yaml", Chart: "app"}},
+		{"app/charts/redis/templates/master.yaml", &ResourceSource{Template: "app/charts/redis/templates/master.yaml", Chart: "redis", Subchart: "redis"}},
+		{"app/charts/redis/charts/common/templates/cm.yaml", &ResourceSource{Template: "app/charts/redis/charts/common/templates/cm.yaml", Chart: "common", Subchart: "redis/common"}},
+		// A template directory named "charts" is not a subchart
+		{"app/templates/charts/cm.yaml", &ResourceSource{Template: "app/templates/charts/cm.yaml", Chart: "app"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewResourceSource(tt.template))
+		})
+	}
+}
+
+func TestParseManifests_Source(t *testing.T) {
+	manifest := `---
+# Source: app/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+---
+# Source: app/charts/redis/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: redis
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: plain
+`
+	resources, err := ParseManifests(manifest)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	assert.Equal(t, &ResourceSource{Template: "app/templates/configmap.yaml", Chart: "app"}, resources[0].Source)
+	assert.Equal(t, "redis", resources[1].Source.Subchart)
+	assert.Nil(t, resources[2].Source)
+}
+
+func TestCompare_GroupBySubchart(t *testing.T) {
+	before := `# Source: app/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  key: a
+---
+# Source: app/charts/redis/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: redis
+data:
+  maxmemory: 1gb
+`
+	after := `# Source: app/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  key: b
+---
+# Source: app/charts/redis/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: redis
+data:
+  maxmemory: 2gb
+  policy: lru
+---
+# Source: app/charts/redis/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: redis
+`
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+
+	groups := GroupBySubchart(result.Resources)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "", groups[0].Subchart)
+	assert.Equal(t, "app", groups[0].Chart)
+	assert.Len(t, groups[0].Resources, 1)
+	assert.Equal(t, "redis", groups[1].Subchart)
+	assert.Len(t, groups[1].Resources, 2)
+
+	require.Len(t, result.Stats.BySubchart, 2)
+	redis := result.Stats.BySubchart[1]
+	assert.Equal(t, StatsResources{Added: 1, Modified: 1}, redis.Resources)
+	assert.Equal(t, 2, redis.Changes.Total)
+	assert.Contains(t, result.Raw, "Source: app/charts/redis/templates/service.yaml")
+}
+
+func TestCompare_NoSourcesNoSubchartStats(t *testing.T) {
+	before := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\ndata:\n  k: a\n"
+	after := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\ndata:\n  k: b\n"
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	assert.Nil(t, result.Stats.BySubchart)
+	assert.Nil(t, result.Resources[0].Source)
+}
@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// isKindSuppressed reports whether resources of the given kind are excluded from the diff.
+// Kinds are matched case-insensitively.
+func (e *Engine) isKindSuppressed(kind string) bool {
+	for _, suppressed := range e.SuppressKinds {
+		if strings.EqualFold(strings.TrimSpace(suppressed), kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterSuppressedKinds drops resources of suppressed kinds from both sides and
+// returns the number of distinct resources removed
+func (e *Engine) filterSuppressedKinds(left, right map[ResourceKey]Resource) int {
+	if len(e.SuppressKinds) == 0 {
+		return 0
+	}
+
+	suppressed := make(map[ResourceKey]bool)
+	for _, resources := range []map[ResourceKey]Resource{left, right} {
+		for key := range resources {
+			if e.isKindSuppressed(key.Kind) {
+				delete(resources, key)
+				suppressed[key] = true
+			}
+		}
+	}
+	return len(suppressed)
+}
+
+// filterSuppressedChanges removes changes whose path or rendered line matches SuppressRegex.
+// Returns the remaining changes and the number removed.
+func (e *Engine) filterSuppressedChanges(changes []Change) ([]Change, int) {
+	if e.SuppressRegex == nil {
+		return changes, 0
+	}
+
+	kept := make([]Change, 0, len(changes))
+	for _, change := range changes {
+		if e.isChangeSuppressed(change) {
+			continue
+		}
+		kept = append(kept, change)
+	}
+	return kept, len(changes) - len(kept)
+}
+
+// isChangeSuppressed matches the regex against the change path and the
+// rendered "path: value" line for both sides of the change
+func (e *Engine) isChangeSuppressed(change Change) bool {
+	if e.SuppressRegex.MatchString(change.Path) {
+		return true
+	}
+	if change.Before != nil && e.SuppressRegex.MatchString(e.renderLine(change.Path, change.Before)) {
+		return true
+	}
+	if change.After != nil && e.SuppressRegex.MatchString(e.renderLine(change.Path, change.After)) {
+		return true
+	}
+	return false
+}
+
+// renderLine renders a change value the way it appears in the raw diff output
+func (e *Engine) renderLine(path string, value interface{}) string {
+	return fmt.Sprintf("%s: %s", path, e.formatValue(value))
+}
+
+// suppressionRules returns the normalization rules describing active suppressions
+func (e *Engine) suppressionRules() []string {
+	rules := []string{}
+
+	if len(e.SuppressKinds) > 0 {
+		kinds := make([]string, len(e.SuppressKinds))
+		copy(kinds, e.SuppressKinds)
+		sort.Strings(kinds)
+		rules = append(rules, "suppressKinds:"+strings.Join(kinds, ","))
+	}
+	if e.SuppressRegex != nil {
+		rules = append(rules, "suppressRegex:"+e.SuppressRegex.String())
+	}
+
+	return rules
+}
@@ -0,0 +1,149 @@
+package diff
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineCompare_SuppressKinds(t *testing.T) {
+	engine := NewEngine()
+	engine.SuppressKinds = []string{"customresourcedefinition", "Secret"}
+
+	manifest1 := `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value1
+`
+
+	manifest2 := `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.org
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+data:
+  password: c2VjcmV0
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value2
+`
+
+	result, err := engine.Compare(manifest1, manifest2)
+	require.NoError(t, err)
+
+	require.Len(t, result.Resources, 1)
+	assert.Equal(t, "ConfigMap", result.Resources[0].Kind)
+	assert.Equal(t, 0, result.Stats.Resources.Added)
+
+	require.NotNil(t, result.Stats.Suppressed)
+	assert.Equal(t, 2, result.Stats.Suppressed.Resources)
+	assert.Equal(t, 0, result.Stats.Suppressed.Changes)
+	assert.Contains(t, result.Metadata.NormalizationRules, "suppressKinds:Secret,customresourcedefinition")
+}
+
+func TestEngineCompare_SuppressRegex(t *testing.T) {
+	engine := NewEngine()
+	engine.SuppressRegex = regexp.MustCompile(`checksum/|image: .*:latest`)
+
+	manifest1 := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 2
+  template:
+    metadata:
+      annotations:
+        checksum/config: abc
+    spec:
+      containers:
+      - name: app
+        image: api:v1
+        imagePullPolicy: IfNotPresent
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: abc
+`
+
+	manifest2 := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 3
+  template:
+    metadata:
+      annotations:
+        checksum/config: def
+    spec:
+      containers:
+      - name: app
+        image: api:latest
+        imagePullPolicy: IfNotPresent
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: def
+`
+
+	result, err := engine.Compare(manifest1, manifest2)
+	require.NoError(t, err)
+
+	// worker only had suppressed changes, so it is not reported as modified
+	require.Len(t, result.Resources, 1)
+	assert.Equal(t, "api", result.Resources[0].Name)
+	require.Len(t, result.Resources[0].Changes, 1)
+	assert.Equal(t, "spec.replicas", result.Resources[0].Changes[0].Path)
+
+	assert.Equal(t, 1, result.Stats.Resources.Modified)
+	assert.Equal(t, 1, result.Stats.Changes.Total)
+	require.NotNil(t, result.Stats.Suppressed)
+	assert.Equal(t, 3, result.Stats.Suppressed.Changes)
+	assert.Contains(t, result.Metadata.NormalizationRules, "suppressRegex:checksum/|image: .*:latest")
+}
+
+func TestEngineCompare_NoSuppressionStats(t *testing.T) {
+	engine := NewEngine()
+
+	result, err := engine.Compare("", "")
+	require.NoError(t, err)
+	assert.Nil(t, result.Stats.Suppressed)
+}
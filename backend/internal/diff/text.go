@@ -0,0 +1,338 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContextLines is the number of unchanged lines shown around each hunk of a text diff
+const DefaultContextLines = 3
+
+// MaxContextLines is the largest number of context lines a text diff accepts
+const MaxContextLines = 100
+
+// lineOp is one line of a line diff: ' ' unchanged, '-' removed or '+' added
+type lineOp struct {
+	kind byte
+	text string
+}
+
+// DiffText compares two text artifacts line by line and returns nil if they are identical
+func DiffText(name, before, after string, contextLines int) *ArtifactDiff {
+	if before == after {
+		return nil
+	}
+
+	artifact := &ArtifactDiff{
+		Name:       name,
+		ChangeType: ChangeTypeModified,
+		Before:     before,
+		After:      after,
+		Diff:       unifiedDiff(name, before, after, contextLines),
+	}
+	switch {
+	case before == "":
+		artifact.ChangeType = ChangeTypeAdded
+	case after == "":
+		artifact.ChangeType = ChangeTypeRemoved
+	}
+	return artifact
+}
+
+// AddArtifact records a text artifact diff and appends it to the raw output
+func (r *DiffResult) AddArtifact(artifact *ArtifactDiff) {
+	if artifact == nil {
+		return
+	}
+	r.Artifacts = append(r.Artifacts, *artifact)
+	r.Raw += fmt.Sprintf("--- %s ---\nChange Type: %s\n%s\n", artifact.Name, artifact.ChangeType, artifact.Diff)
+}
+
+// splitLines splits text into lines, ignoring a trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// maxDiffSteps bounds the work of a line diff. Texts that differ more are
+// reported as all old lines removed and all new lines added.
+const maxDiffSteps = 10_000_000
+
+// diffLines computes a minimal line diff with Myers' linear space algorithm.
+// Common leading and trailing lines are matched first. If the texts differ too
+// much to diff within maxDiffSteps, the differing middle is replaced as a whole.
+func diffLines(a, b []string) []lineOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]lineOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, lineOp{' ', line})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	d := &lineDiff{steps: maxDiffSteps}
+	if d.diff(midA, midB) {
+		ops = append(ops, d.ops...)
+	} else {
+		ops = appendLines(ops, '-', midA)
+		ops = appendLines(ops, '+', midB)
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, lineOp{' ', line})
+	}
+	return ops
+}
+
+// lineDiff accumulates the ops of a Myers diff and the work left for computing it
+type lineDiff struct {
+	ops   []lineOp
+	steps int
+}
+
+// diff appends the ops turning a into b. Returns false if it ran out of steps.
+func (d *lineDiff) diff(a, b []string) bool {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	d.ops = appendLines(d.ops, ' ', a[:prefix])
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		d.ops = appendLines(d.ops, '+', b)
+	case len(b) == 0:
+		d.ops = appendLines(d.ops, '-', a)
+	default:
+		x, y, ok := d.bisect(a, b)
+		if !ok {
+			return false
+		}
+		if x == 0 && y == 0 || x == len(a) && y == len(b) {
+			// No common line: the middle snake is empty at an end
+			d.ops = appendLines(d.ops, '-', a)
+			d.ops = appendLines(d.ops, '+', b)
+		} else if !d.diff(a[:x], b[:y]) || !d.diff(a[x:], b[y:]) {
+			return false
+		}
+	}
+
+	d.ops = appendLines(d.ops, ' ', common)
+	return true
+}
+
+// bisect finds the middle snake of the shortest edit script turning a into b by
+// searching forward from the start and backward from the end at once, and returns
+// the point where the two searches meet. Memory is linear in len(a)+len(b).
+func (d *lineDiff) bisect(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// With an odd delta the forward search meets the backward one, otherwise the reverse
+	odd := delta%2 != 0
+	k1start, k1end, k2start, k2end := 0, 0, 0, 0
+
+	for D := 0; D < maxD; D++ {
+		for k1 := -D + k1start; k1 <= D-k1end; k1 += 2 {
+			i := offset + k1
+			var x int
+			if k1 == -D || k1 != D && forward[i-1] < forward[i+1] {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k1
+			start := x
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+			if d.steps -= x - start + 1; d.steps < 0 {
+				return 0, 0, false
+			}
+			switch {
+			case x > n:
+				k1end += 2
+			case y > m:
+				k1start += 2
+			case odd:
+				j := offset + delta - k1
+				if j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k2 := -D + k2start; k2 <= D-k2end; k2 += 2 {
+			i := offset + k2
+			var x int
+			if k2 == -D || k2 != D && backward[i-1] < backward[i+1] {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k2
+			start := x
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+			if d.steps -= x - start + 1; d.steps < 0 {
+				return 0, 0, false
+			}
+			switch {
+			case x > n:
+				k2end += 2
+			case y > m:
+				k2start += 2
+			case !odd:
+				j := offset + delta - k2
+				if j >= 0 && j < len(forward) && forward[j] != -1 {
+					x1 := forward[j]
+					y1 := offset + x1 - j
+					if x1 >= n-x {
+						return x1, y1, true
+					}
+				}
+			}
+		}
+	}
+
+	// The searches did not meet, so the texts have no line in common
+	return 0, 0, true
+}
+
+// appendLines appends an op of the given kind for each line
+func appendLines(ops []lineOp, kind byte, lines []string) []lineOp {
+	for _, line := range lines {
+		ops = append(ops, lineOp{kind, line})
+	}
+	return ops
+}
+
+// unifiedDiff renders a line diff in unified format with contextLines of context around each hunk
+func unifiedDiff(name, before, after string, contextLines int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- a/%s\n+++ b/%s\n", name, name))
+	writeHunks(&sb, "", diffHunks(before, after, contextLines))
+	return sb.String()
+}
+
+// diffHunks computes the hunks of a line diff with contextLines of context around each change
+func diffHunks(before, after string, contextLines int) []TextHunk {
+	if contextLines < 0 {
+		contextLines = 0
+	}
+	if contextLines > MaxContextLines {
+		contextLines = MaxContextLines
+	}
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	// Line positions in each text before every op, for hunk headers
+	oldPos := make([]int, len(ops)+1)
+	newPos := make([]int, len(ops)+1)
+	for k, op := range ops {
+		oldPos[k+1], newPos[k+1] = oldPos[k], newPos[k]
+		if op.kind != '+' {
+			oldPos[k+1]++
+		}
+		if op.kind != '-' {
+			newPos[k+1]++
+		}
+	}
+
+	var hunks []TextHunk
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Extend the hunk while the next change is close enough to share context
+		last := i
+		for j := i + 1; j < len(ops) && j-last <= 2*contextLines+1; j++ {
+			if ops[j].kind != ' ' {
+				last = j
+			}
+		}
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		stop := last + contextLines + 1
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		hunk := TextHunk{
+			OldStart: hunkStart(oldPos[start], oldPos[stop]-oldPos[start]),
+			OldLines: oldPos[stop] - oldPos[start],
+			NewStart: hunkStart(newPos[start], newPos[stop]-newPos[start]),
+			NewLines: newPos[stop] - newPos[start],
+			Lines:    make([]string, 0, stop-start),
+		}
+		for _, op := range ops[start:stop] {
+			hunk.Lines = append(hunk.Lines, string(op.kind)+op.text)
+		}
+		hunks = append(hunks, hunk)
+		i = stop
+	}
+	return hunks
+}
+
+// hunkStart returns the 1-based first line of a hunk range starting after pos lines.
+// An empty range starts at the line before it, as in unified diff headers.
+func hunkStart(pos, count int) int {
+	if count == 0 {
+		return pos
+	}
+	return pos + 1
+}
+
+// writeHunks writes hunks in unified format, each line prefixed with indent
+func writeHunks(sb *strings.Builder, indent string, hunks []TextHunk) {
+	for _, hunk := range hunks {
+		sb.WriteString(fmt.Sprintf("%s@@ -%s +%s @@\n", indent,
+			hunkRange(hunk.OldStart, hunk.OldLines), hunkRange(hunk.NewStart, hunk.NewLines)))
+		for _, line := range hunk.Lines {
+			sb.WriteString(indent)
+			sb.WriteString(line)
+			sb.WriteByte('\n')
+		}
+	}
+}
+
+// hunkRange formats the start,count range of a unified diff hunk header
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
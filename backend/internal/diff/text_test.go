@@ -0,0 +1,234 @@
+package diff
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffText(t *testing.T) {
+	assert.Nil(t, DiffText("NOTES.txt", "same\n", "same\n", DefaultContextLines))
+
+	before := "line 1\nline 2\nline 3\nline 4\nline 5\nline 6\nline 7\nline 8\nline 9\nline 10\n"
+	after := "line 1\nline 2 changed\nline 3\nline 4\nline 5\nline 6\nline 7\nline 8\nline 9\nline 10\nline 11\n"
+
+	artifact := DiffText("NOTES.txt", before, after, 1)
+	require.NotNil(t, artifact)
+	assert.Equal(t, ChangeTypeModified, artifact.ChangeType)
+	assert.Equal(t, `--- a/NOTES.txt
++++ b/NOTES.txt
+@@ -1,3 +1,3 @@
+ line 1
+-line 2
++line 2 changed
+ line 3
+@@ -10 +10,2 @@
+ line 10
++line 11
+`, artifact.Diff)
+
+	// Nearby changes share a hunk
+	artifact = DiffText("NOTES.txt", "a\nb\nc\nd\n", "A\nb\nc\nD\n", 1)
+	require.NotNil(t, artifact)
+	assert.Equal(t, `--- a/NOTES.txt
++++ b/NOTES.txt
+@@ -1,4 +1,4 @@
+-a
++A
+ b
+ c
+-d
++D
+`, artifact.Diff)
+
+	added := DiffText("NOTES.txt", "", "Installed.\n", DefaultContextLines)
+	require.NotNil(t, added)
+	assert.Equal(t, ChangeTypeAdded, added.ChangeType)
+	assert.Contains(t, added.Diff, "@@ -0,0 +1 @@\n+Installed.\n")
+}
+
+func TestDiffResult_AddArtifact(t *testing.T) {
+	result := &DiffResult{Raw: "=== Diff Summary ===\n"}
+	result.AddArtifact(nil)
+	assert.Empty(t, result.Artifacts)
+
+	result.AddArtifact(DiffText("NOTES.txt", "old\n", "new\n", DefaultContextLines))
+	require.Len(t, result.Artifacts, 1)
+	assert.Contains(t, result.Raw, "--- NOTES.txt ---\nChange Type: modified\n")
+	assert.Contains(t, result.Raw, "-old\n+new\n")
+}
+
+func TestDiffHunks(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\n"
+	after := "a\nB\nc\nd\ne\nf\nG\n"
+
+	hunks := diffHunks(before, after, 1)
+	require.Len(t, hunks, 2)
+	assert.Equal(t, TextHunk{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3, Lines: []string{" a", "-b", "+B", " c"}}, hunks[0])
+	assert.Equal(t, TextHunk{OldStart: 6, OldLines: 2, NewStart: 6, NewLines: 2, Lines: []string{" f", "-g", "+G"}}, hunks[1])
+
+	// Wider context merges the hunks
+	require.Len(t, diffHunks(before, after, 3), 1)
+
+	// No context shows only the changed lines
+	hunks = diffHunks(before, after, 0)
+	require.Len(t, hunks, 2)
+	assert.Equal(t, []string{"-b", "+B"}, hunks[0].Lines)
+
+	assert.Nil(t, diffHunks(before, before, 3))
+}
+
+func TestDiffHunks_HugeContextIsClamped(t *testing.T) {
+	before := "a\nb\nc\n"
+	after := "a\nB\nc\n"
+
+	for _, contextLines := range []int{math.MaxInt, math.MaxInt - 1, MaxContextLines + 1} {
+		hunks := diffHunks(before, after, contextLines)
+		require.Len(t, hunks, 1)
+		assert.Equal(t, []string{" a", "-b", "+B", " c"}, hunks[0].Lines)
+	}
+}
+
+func TestDiffLines_Minimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = strconv.Itoa(rng.Intn(4))
+		}
+		return lines
+	}
+
+	for iteration := 0; iteration < 500; iteration++ {
+		a, b := randomLines(), randomLines()
+		ops := diffLines(a, b)
+
+		var gotA, gotB []string
+		edits := 0
+		for _, op := range ops {
+			if op.kind != '+' {
+				gotA = append(gotA, op.text)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.text)
+			}
+			if op.kind != ' ' {
+				edits++
+			}
+		}
+		require.Equal(t, len(a), len(gotA), "%v -> %v", a, b)
+		require.Equal(t, len(b), len(gotB), "%v -> %v", a, b)
+		for i := range a {
+			require.Equal(t, a[i], gotA[i], "%v -> %v", a, b)
+		}
+		for i := range b {
+			require.Equal(t, b[i], gotB[i], "%v -> %v", a, b)
+		}
+		assert.Equal(t, len(a)+len(b)-2*lcsLength(a, b), edits, "%v -> %v", a, b)
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] >= cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestDiffLines_LargeTextsFallBackToReplace(t *testing.T) {
+	// Every line differs, with edits at both ends defeating prefix and suffix matching
+	a := make([]string, 20000)
+	b := make([]string, 20000)
+	for i := range a {
+		a[i] = "old " + strconv.Itoa(i)
+		b[i] = "new " + strconv.Itoa(i)
+	}
+	a[10000], b[10000] = "shared", "shared"
+
+	ops := diffLines(a, b)
+	require.Len(t, ops, 40000, "the texts are replaced as a whole")
+	assert.Equal(t, lineOp{'-', "old 0"}, ops[0])
+	assert.Equal(t, lineOp{'-', "old 19999"}, ops[19999])
+	assert.Equal(t, lineOp{'+', "new 0"}, ops[20000])
+	require.Len(t, diffHunks(strings.Join(a, "\n"), strings.Join(b, "\n"), 3), 1)
+
+	// Few edits far apart in a large text are still diffed line by line
+	c := make([]string, 100000)
+	for i := range c {
+		c[i] = "line " + strconv.Itoa(i)
+	}
+	d := append([]string(nil), c...)
+	d[1], d[99998] = "first edit", "last edit"
+	require.Len(t, diffHunks(strings.Join(c, "\n"), strings.Join(d, "\n"), 3), 2)
+}
+
+func TestEngineCompare_MultilineStringHunks(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  annotations:
+    policy: |
+      package main
+      default allow = false
+      allow { input.user == "%s" }
+      deny { input.size > 10 }
+spec:
+  containers:
+    - name: app
+      image: nginx:1.25
+      args: ["--name", "%s"]
+`
+	before := fmt.Sprintf(manifest, "admin", "a")
+	after := fmt.Sprintf(manifest, "root", "b")
+
+	result, err := NewEngine().Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 1)
+
+	policy := findChange(result.Resources[0].Changes, "metadata.annotations.policy")
+	require.NotNil(t, policy)
+	require.Len(t, policy.Hunks, 1)
+	assert.Equal(t, []string{
+		" package main",
+		" default allow = false",
+		`-allow { input.user == "admin" }`,
+		`+allow { input.user == "root" }`,
+		" deny { input.size > 10 }",
+	}, policy.Hunks[0].Lines)
+	assert.Contains(t, result.Raw, "    @@ -1,4 +1,4 @@\n")
+	assert.NotContains(t, result.Raw, `    - package main`, "the full value is not repeated")
+
+	arg := findChange(result.Resources[0].Changes, "spec.containers[name=app].args.1")
+	require.NotNil(t, arg)
+	assert.Nil(t, arg.Hunks, "single-line strings have no line diff")
+
+	contextLines := 0
+	engine := NewEngine()
+	engine.ContextLines = &contextLines
+	result, err = engine.Compare(before, after)
+	require.NoError(t, err)
+	policy = findChange(result.Resources[0].Changes, "metadata.annotations.policy")
+	require.NotNil(t, policy)
+	assert.Equal(t, []string{`-allow { input.user == "admin" }`, `+allow { input.user == "root" }`}, policy.Hunks[0].Lines)
+}
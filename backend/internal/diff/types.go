@@ -2,10 +2,16 @@ package diff
 
 // DiffResult represents the structured output of a diff operation (v1 spec)
 type DiffResult struct {
-	Metadata  DiffMetadata   `json:"metadata"`
-	Resources []ResourceDiff `json:"resources"`
-	Stats     *Stats         `json:"stats,omitempty"`
-	Raw       string         `json:"raw,omitempty"` // For backward compatibility
+	Metadata          DiffMetadata       `json:"metadata"`
+	Resources         []ResourceDiff     `json:"resources"`
+	Stats             *Stats             `json:"stats,omitempty"`
+	Artifacts         []ArtifactDiff     `json:"artifacts,omitempty"`         // Non-resource outputs, e.g. NOTES.txt
+	Chart             *ChartDiff         `json:"chart,omitempty"`             // Chart.yaml and Chart.lock changes
+	Values            *ValuesDiff        `json:"values,omitempty"`            // Chart default values changes
+	Warnings          []ValuesWarning    `json:"warnings,omitempty"`          // Problems with the supplied values
+	ParseWarnings     []ParseWarning     `json:"parseWarnings,omitempty"`     // Manifest documents that were skipped
+	IdentityConflicts []IdentityConflict `json:"identityConflicts,omitempty"` // Resources colliding on one side
+	Raw               string             `json:"raw,omitempty"`               // For backward compatibility
 
 	// Legacy fields for backward compatibility
 	Summary Summary `json:"summary,omitempty"`
@@ -18,8 +24,12 @@ type DiffMetadata struct {
 	GeneratedAt        string        `json:"generatedAt"` // RFC3339 timestamp
 	Inputs             InputMetadata `json:"inputs"`
 	NormalizationRules []string      `json:"normalizationRules,omitempty"`
+	ComparisonMode     string        `json:"comparisonMode,omitempty"` // "values" for a values-only diff
 }
 
+// ComparisonModeValues marks a diff of one chart version rendered with two values sets
+const ComparisonModeValues = "values"
+
 // InputMetadata describes the sources being compared
 type InputMetadata struct {
 	Left  SourceMetadata `json:"left"`
@@ -28,7 +38,8 @@ type InputMetadata struct {
 
 // SourceMetadata describes a single input source
 type SourceMetadata struct {
-	Source     string `json:"source"` // e.g., "helm", "kustomize"
+	Source     string `json:"source"`               // e.g., "helm", "git", "helm-repo", "oci", "tarball", "kustomize"
+	Repository string `json:"repository,omitempty"` // Repository URL or directory the input was fetched from
 	Chart      string `json:"chart,omitempty"`
 	Version    string `json:"version,omitempty"`
 	ValuesHash string `json:"valuesHash,omitempty"`
@@ -36,8 +47,10 @@ type SourceMetadata struct {
 
 // Stats provides aggregate statistics about the diff
 type Stats struct {
-	Resources StatsResources `json:"resources"`
-	Changes   StatsChanges   `json:"changes"`
+	Resources  StatsResources   `json:"resources"`
+	Changes    StatsChanges     `json:"changes"`
+	Suppressed *StatsSuppressed `json:"suppressed,omitempty"`
+	BySubchart []SubchartStats  `json:"bySubchart,omitempty"` // Per (sub)chart breakdown of Helm output
 }
 
 // StatsResources provides resource-level statistics
@@ -45,6 +58,8 @@ type StatsResources struct {
 	Added    int `json:"added"`
 	Removed  int `json:"removed"`
 	Modified int `json:"modified"`
+	Renamed  int `json:"renamed,omitempty"`
+	Moved    int `json:"moved,omitempty"`
 }
 
 // StatsChanges provides change-level statistics
@@ -52,7 +67,23 @@ type StatsChanges struct {
 	Total int `json:"total"`
 }
 
-// Summary provides high-level statistics about the diff (legacy)
+// StatsSuppressed reports what was filtered out by suppression rules
+type StatsSuppressed struct {
+	Resources int `json:"resources"` // Resources dropped by SuppressKinds
+	Changes   int `json:"changes"`   // Changes dropped by SuppressRegex
+}
+
+// SubchartStats provides statistics for the resources rendered by one (sub)chart
+type SubchartStats struct {
+	Subchart  string         `json:"subchart"` // Subchart path, e.g. "redis" or "app/redis"; empty for the parent chart
+	Chart     string         `json:"chart"`
+	Resources StatsResources `json:"resources"`
+	Changes   StatsChanges   `json:"changes"`
+}
+
+// Summary provides high-level statistics about the diff (legacy).
+// Renamed and moved resources count as Modified, so Modified equals
+// Stats.Resources.Modified + Renamed + Moved.
 type Summary struct {
 	Added    int `json:"added"`
 	Removed  int `json:"removed"`
@@ -68,6 +99,16 @@ type ResourceDiff struct {
 	AfterHash  string           `json:"afterHash,omitempty"`
 	Changes    []Change         `json:"changes,omitempty"`
 	Summary    *ResourceSummary `json:"summary,omitempty"`
+	Hook       *HookInfo        `json:"hook,omitempty"`   // Set for Helm hooks and test resources
+	Source     *ResourceSource  `json:"source,omitempty"` // Template that rendered the resource
+
+	// Full normalized object of an added or removed resource, set with Engine.IncludeObjects
+	Object map[string]interface{} `json:"object,omitempty"`
+
+	// Set for renamed and moved resources
+	PreviousIdentity *ResourceIdentity `json:"previousIdentity,omitempty"`
+	Similarity       float64           `json:"similarity,omitempty"` // Content similarity between 0 and 1
+	Recreate         bool              `json:"recreate,omitempty"`   // Kubernetes deletes and recreates the object
 
 	// Legacy fields for backward compatibility
 	APIVersion string      `json:"apiVersion,omitempty"`
@@ -86,6 +127,94 @@ type ResourceIdentity struct {
 	UID        *string `json:"uid"` // Optional, usually unavailable in Helm renders
 }
 
+// HookInfo describes a Helm hook resource, from its helm.sh/hook* annotations
+type HookInfo struct {
+	Events         []string `json:"events"`                   // e.g. "pre-upgrade", "post-install", "test"
+	Weight         int      `json:"weight"`                   // helm.sh/hook-weight, 0 if unset
+	DeletePolicies []string `json:"deletePolicies,omitempty"` // e.g. "before-hook-creation", "hook-succeeded"
+}
+
+// ArtifactDiff is a line diff of a text output that is not a Kubernetes resource
+type ArtifactDiff struct {
+	Name       string     `json:"name"` // e.g. "NOTES.txt"
+	ChangeType ChangeType `json:"changeType"`
+	Before     string     `json:"before,omitempty"`
+	After      string     `json:"after,omitempty"`
+	Diff       string     `json:"diff"` // Unified diff
+}
+
+// ChartDiff describes changes to chart metadata (Chart.yaml) and subcharts (Chart.lock)
+type ChartDiff struct {
+	Changes      []ChartFieldChange `json:"changes,omitempty"`
+	Dependencies []DependencyChange `json:"dependencies,omitempty"`
+}
+
+// ChartFieldChange is a changed Chart.yaml field
+type ChartFieldChange struct {
+	Field       string      `json:"field"` // e.g. "appVersion", "kubeVersion", "type", "maintainers"
+	Before      interface{} `json:"before,omitempty"`
+	After       interface{} `json:"after,omitempty"`
+	VersionBump string      `json:"versionBump,omitempty"` // major|minor|patch|prerelease, for version fields
+	Downgrade   bool        `json:"downgrade,omitempty"`
+}
+
+// DependencyChange is an added, removed or changed subchart
+type DependencyChange struct {
+	Name             string     `json:"name"` // Alias if set, otherwise chart name
+	ChangeType       ChangeType `json:"changeType"`
+	BeforeVersion    string     `json:"beforeVersion,omitempty"` // Locked version, or the Chart.yaml constraint without a lock
+	AfterVersion     string     `json:"afterVersion,omitempty"`
+	BeforeRepository string     `json:"beforeRepository,omitempty"`
+	AfterRepository  string     `json:"afterRepository,omitempty"`
+	VersionBump      string     `json:"versionBump,omitempty"` // major|minor|patch|prerelease
+	Downgrade        bool       `json:"downgrade,omitempty"`
+}
+
+// ValuesDiff splits chart default value changes by whether they reach the rendered output
+type ValuesDiff struct {
+	Inherited []DefaultValueChange `json:"inherited,omitempty"` // Not overridden by the supplied values
+	Masked    []DefaultValueChange `json:"masked,omitempty"`    // Overridden by the supplied values
+}
+
+// DefaultValueChange is a changed key in the chart's default values
+type DefaultValueChange struct {
+	Path     string      `json:"path"` // --set style path, e.g. "image.tag"
+	Op       OpType      `json:"op"`
+	Before   interface{} `json:"before,omitempty"`
+	After    interface{} `json:"after,omitempty"`
+	Override interface{} `json:"override,omitempty"` // Supplied value masking the change
+}
+
+// ValuesWarning is a problem with the supplied values found on one side of a comparison
+type ValuesWarning struct {
+	Type      string `json:"type"`                // WarningOrphanedValue, WarningLikelyRename or WarningSchema
+	Side      string `json:"side,omitempty"`      // "left" or "right" for schema errors
+	Path      string `json:"path,omitempty"`      // --set style path of the offending value
+	RenamedTo string `json:"renamedTo,omitempty"` // Likely new path of a renamed key
+	Message   string `json:"message"`
+}
+
+// ParseWarning describes a manifest document that was skipped or only partly used
+type ParseWarning struct {
+	Side     string `json:"side,omitempty"` // "left" or "right"
+	Document int    `json:"document"`       // 1-based position of the document in the stream
+	Line     int    `json:"line"`           // Line the document starts at
+	Message  string `json:"message"`
+}
+
+// IdentityConflict is a set of resources on one side that share an object identity
+type IdentityConflict struct {
+	Side        string   `json:"side,omitempty"` // "left" or "right"
+	Type        string   `json:"type"`           // IdentityConflictDuplicate or IdentityConflictAPIVersion
+	Kind        string   `json:"kind"`
+	Name        string   `json:"name"`
+	Namespace   string   `json:"namespace,omitempty"`
+	APIVersions []string `json:"apiVersions"`
+	Count       int      `json:"count"`             // Number of colliding documents
+	Sources     []string `json:"sources,omitempty"` // Templates that rendered them
+	Message     string   `json:"message"`
+}
+
 // ResourceSummary provides a derived summary of changes for a resource
 type ResourceSummary struct {
 	TotalChanges int            `json:"totalChanges"`
@@ -100,22 +229,70 @@ const (
 	ChangeTypeAdded     ChangeType = "added"
 	ChangeTypeRemoved   ChangeType = "removed"
 	ChangeTypeModified  ChangeType = "modified"
+	ChangeTypeRenamed   ChangeType = "renamed" // Same content under a new name
+	ChangeTypeMoved     ChangeType = "moved"   // Same content in a new namespace
 	ChangeTypeUnchanged ChangeType = "unchanged"
 )
 
 // Change represents a field-level change with full context
 type Change struct {
-	Op             OpType      `json:"op"`
-	Path           string      `json:"path"`
-	PathTokens     []PathToken `json:"pathTokens"`
-	Before         interface{} `json:"before,omitempty"`
-	After          interface{} `json:"after,omitempty"`
-	ValueType      string      `json:"valueType"`
-	SemanticType   string      `json:"semanticType,omitempty"`
-	ChangeCategory string      `json:"changeCategory,omitempty"`
-	Importance     string      `json:"importance,omitempty"`
-	Flags          []string    `json:"flags,omitempty"`
-	ArrayDiff      *ArrayDiff  `json:"arrayDiff,omitempty"`
+	Op         OpType      `json:"op"`
+	Path       string      `json:"path"`
+	PathTokens []PathToken `json:"pathTokens"`
+	Pointer    string      `json:"pointer"` // RFC 6901 JSON Pointer, e.g. /metadata/annotations/app.kubernetes.io~1name
+
+	// RFC 6901 JSON Pointer into a document embedded in the string at Pointer,
+	// e.g. /server/port for a field of data.application.yaml
+	EmbeddedPointer string      `json:"embeddedPointer,omitempty"`
+	Before          interface{} `json:"before,omitempty"`
+	After           interface{} `json:"after,omitempty"`
+	ValueType       string      `json:"valueType"`
+	SemanticType    string      `json:"semanticType,omitempty"`
+	ChangeCategory  string      `json:"changeCategory,omitempty"`
+	Importance      string      `json:"importance,omitempty"`
+	Flags           []string    `json:"flags,omitempty"`
+	ArrayDiff       *ArrayDiff  `json:"arrayDiff,omitempty"`
+
+	// Set when a resource quantity changed, e.g. a CPU request
+	QuantityDelta *QuantityDelta `json:"quantityDelta,omitempty"`
+
+	// Set when a container image reference changed
+	Image *ImageChange `json:"image,omitempty"`
+
+	// Line diff of a changed multi-line string, e.g. a script or an nginx config
+	Hunks []TextHunk `json:"hunks,omitempty"`
+}
+
+// TextHunk is one hunk of a unified line diff
+type TextHunk struct {
+	OldStart int      `json:"oldStart"` // 1-based first line, or the line before an empty range
+	OldLines int      `json:"oldLines"`
+	NewStart int      `json:"newStart"`
+	NewLines int      `json:"newLines"`
+	Lines    []string `json:"lines"` // Prefixed with " " (context), "-" (removed) or "+" (added)
+}
+
+// QuantityDelta is the numeric difference between two resource quantities
+type QuantityDelta struct {
+	Delta string  `json:"delta"` // Signed quantity, e.g. "+250m" or "-512Mi"
+	Value float64 `json:"value"` // Delta in base units, e.g. cores or bytes
+}
+
+// ImageReference is a container image reference split into its parts
+type ImageReference struct {
+	Registry   string `json:"registry"`         // e.g. "docker.io" or "ghcr.io"
+	Repository string `json:"repository"`       // e.g. "library/nginx"
+	Tag        string `json:"tag,omitempty"`    // Empty if the reference has none
+	Digest     string `json:"digest,omitempty"` // e.g. "sha256:..."
+}
+
+// ImageChange classifies a change between two image references
+type ImageChange struct {
+	Before      ImageReference `json:"before"`
+	After       ImageReference `json:"after"`
+	Kinds       []string       `json:"kinds"`                 // ImageChangeRegistry, ImageChangeTag, ...
+	VersionBump string         `json:"versionBump,omitempty"` // major|minor|patch|prerelease, for semver tags
+	Downgrade   bool           `json:"downgrade,omitempty"`
 }
 
 // OpType represents JSON Patch-style operation types
@@ -155,6 +332,14 @@ type Resource struct {
 	Spec       map[string]interface{} `json:"spec,omitempty"`
 	Data       map[string]interface{} `json:"data,omitempty"`
 	Other      map[string]interface{} `json:"-"` // Other fields
+	Source     *ResourceSource        `json:"-"` // From the "# Source:" comment of Helm output
+}
+
+// ResourceSource is the template and (sub)chart a resource was rendered from
+type ResourceSource struct {
+	Template string `json:"template"`           // e.g. "app/charts/redis/templates/master.yaml"
+	Chart    string `json:"chart"`              // Name of the chart owning the template, e.g. "redis"
+	Subchart string `json:"subchart,omitempty"` // Subchart path below the parent chart, e.g. "redis"
 }
 
 // Metadata represents Kubernetes resource metadata
@@ -42,8 +42,9 @@ data:
 	assert.Equal(t, "helm", result.Metadata.Inputs.Left.Source)
 	assert.Equal(t, "helm", result.Metadata.Inputs.Right.Source)
 
-	// Verify normalization rules
-	assert.Contains(t, result.Metadata.NormalizationRules, "normalizeDefaults")
+	// Verify normalization rules: a ConfigMap has no API defaults to strip
+	assert.Contains(t, result.Metadata.NormalizationRules, "secretHandling:suppress")
+	assert.NotContains(t, result.Metadata.NormalizationRules, "normalizeDefaults")
 }
 
 // TestResourceIdentity tests the canonical resource identity structure
@@ -403,23 +404,27 @@ data:
 // TestSemanticClassification tests semantic type classification
 func TestSemanticClassification(t *testing.T) {
 	tests := []struct {
-		path         string
+		tokens       []PathToken
 		expectedType string
 		expectedCat  string
 		expectedImp  string
 	}{
-		{"spec.template.spec.containers.0.image", "container.image", "workload", "high"},
-		{"spec.replicas", "workload.replicas", "workload", "high"},
-		{"spec.template.spec.containers.0.resources.limits.cpu", "resources.cpu", "resources", "medium"},
-		{"metadata.labels.app", "metadata.label", "metadata", "low"},
-		{"metadata.annotations.description", "metadata.annotation", "metadata", "low"},
+		{[]PathToken{"spec", "template", "spec", "containers", 0, "image"}, "container.image", "workload", "high"},
+		{[]PathToken{"spec", "replicas"}, "workload.replicas", "workload", "high"},
+		{[]PathToken{"spec", "template", "spec", "containers", 0, "resources", "limits", "cpu"}, "resources.cpu", "resources", "medium"},
+		{[]PathToken{"metadata", "labels", "app"}, "metadata.label", "metadata", "low"},
+		{[]PathToken{"metadata", "annotations", "description"}, "metadata.annotation", "metadata", "low"},
+		{[]PathToken{"metadata", "annotations", "app.kubernetes.io/image"}, "metadata.annotation", "metadata", "low"},
+		{[]PathToken{"spec", "template", "metadata", "annotations", "checksum/config"}, "metadata.annotation", "metadata", "low"},
+		{[]PathToken{"spec", "template", "spec", "imagePullSecrets", 0, "name"}, "", "security", "medium"},
+		{[]PathToken{"spec", "template", "spec", "serviceAccountName"}, "security.serviceAccount", "security", "medium"},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.path, func(t *testing.T) {
-			semType := classifySemanticType(tt.path)
-			category := classifyChangeCategory(tt.path)
-			importance := determineImportance(tt.path, semType)
+		t.Run(JSONPointer(tt.tokens), func(t *testing.T) {
+			semType := classifySemanticType(tt.tokens)
+			category := classifyChangeCategory(tt.tokens)
+			importance := determineImportance(tt.tokens, semType)
 
 			assert.Equal(t, tt.expectedType, semType, "semantic type mismatch")
 			assert.Equal(t, tt.expectedCat, category, "category mismatch")
@@ -0,0 +1,146 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DiffDefaultValues compares the default values of two chart versions and splits
+// the changed leaves by whether they reach the rendered output. A change is masked
+// when overrides (the values supplied for the new version) set the same key, or
+// replace one of its parents with a non-map value. Added or removed maps are
+// compared leaf by leaf so partial overrides are accounted for.
+func DiffDefaultValues(before, after, overrides map[string]interface{}) *ValuesDiff {
+	result := &ValuesDiff{}
+	walkDefaultChanges(nil, before, after, func(path []string, op OpType, v1, v2 interface{}) {
+		change := DefaultValueChange{
+			Path:   valuesPath(path),
+			Op:     op,
+			Before: v1,
+			After:  v2,
+		}
+		if override, ok := lookupOverride(overrides, path); ok {
+			change.Override = override
+			result.Masked = append(result.Masked, change)
+		} else {
+			result.Inherited = append(result.Inherited, change)
+		}
+	})
+	return result
+}
+
+// walkDefaultChanges visits every changed leaf between two values trees in key order
+func walkDefaultChanges(path []string, before, after interface{}, visit func(path []string, op OpType, v1, v2 interface{})) {
+	m1, ok1 := before.(map[string]interface{})
+	m2, ok2 := after.(map[string]interface{})
+	if !ok1 || !ok2 {
+		if !reflect.DeepEqual(before, after) {
+			visit(path, OpReplace, before, after)
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(m1)+len(m2))
+	for k := range m1 {
+		keys = append(keys, k)
+	}
+	for k := range m2 {
+		if _, ok := m1[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		childPath := append(append([]string{}, path...), k)
+		v1, in1 := m1[k]
+		v2, in2 := m2[k]
+		switch {
+		case !in1:
+			walkLeaves(childPath, v2, func(p []string, v interface{}) { visit(p, OpAdd, nil, v) })
+		case !in2:
+			walkLeaves(childPath, v1, func(p []string, v interface{}) { visit(p, OpRemove, v, nil) })
+		default:
+			walkDefaultChanges(childPath, v1, v2, visit)
+		}
+	}
+}
+
+// walkLeaves visits the leaves of a values tree; empty maps are leaves
+func walkLeaves(path []string, value interface{}, visit func(path []string, value interface{})) {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		visit(path, value)
+		return
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		walkLeaves(append(append([]string{}, path...), k), m[k], visit)
+	}
+}
+
+// lookupOverride returns the overriding value for a path: the value at the path
+// itself, or the first non-map value on the way to it
+func lookupOverride(overrides map[string]interface{}, path []string) (interface{}, bool) {
+	var current interface{} = overrides
+	for _, key := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return current, true
+		}
+		if current, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// valuesPath joins values keys like --set paths, escaping dots within keys
+func valuesPath(path []string) string {
+	escaped := make([]string, len(path))
+	for i, key := range path {
+		escaped[i] = strings.ReplaceAll(key, ".", `\.`)
+	}
+	return strings.Join(escaped, ".")
+}
+
+// SetValues records the default values diff and appends it to the raw output
+func (r *DiffResult) SetValues(values *ValuesDiff) {
+	if values == nil || len(values.Inherited) == 0 && len(values.Masked) == 0 {
+		return
+	}
+	r.Values = values
+
+	var sb strings.Builder
+	sb.WriteString("=== Inherited Default Changes ===\n")
+	for _, change := range values.Inherited {
+		writeDefaultValueChange(&sb, change)
+	}
+	if len(values.Masked) > 0 {
+		sb.WriteString("=== Default Changes Masked By Supplied Values ===\n")
+		for _, change := range values.Masked {
+			writeDefaultValueChange(&sb, change)
+			sb.WriteString(fmt.Sprintf("    = %s (supplied)\n", formatChartValue(change.Override)))
+		}
+	}
+	sb.WriteString("\n")
+
+	r.Raw += sb.String()
+}
+
+// writeDefaultValueChange writes one default value change in raw diff format
+func writeDefaultValueChange(sb *strings.Builder, change DefaultValueChange) {
+	sb.WriteString(fmt.Sprintf("  %s [%s]\n", change.Path, change.Op))
+	if change.Op != OpAdd {
+		sb.WriteString(fmt.Sprintf("    - %s\n", formatChartValue(change.Before)))
+	}
+	if change.Op != OpRemove {
+		sb.WriteString(fmt.Sprintf("    + %s\n", formatChartValue(change.After)))
+	}
+}
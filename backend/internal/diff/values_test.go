@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffDefaultValues(t *testing.T) {
+	before := map[string]interface{}{
+		"replicas": 1,
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "1.25"},
+		"resources": map[string]interface{}{
+			"limits": map[string]interface{}{"cpu": "500m"},
+		},
+		"legacy":      true,
+		"annotations": map[string]interface{}{"a.b/c": "x"},
+	}
+	after := map[string]interface{}{
+		"replicas": 2,
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "1.27"},
+		"resources": map[string]interface{}{
+			"limits": map[string]interface{}{"cpu": "1"},
+		},
+		"probes":      map[string]interface{}{"liveness": map[string]interface{}{"enabled": true, "path": "/healthz"}},
+		"annotations": map[string]interface{}{"a.b/c": "y"},
+	}
+	overrides := map[string]interface{}{
+		"image":     map[string]interface{}{"tag": "1.26"},
+		"resources": "none",
+		"probes":    map[string]interface{}{"liveness": map[string]interface{}{"enabled": false}},
+	}
+
+	result := DiffDefaultValues(before, after, overrides)
+
+	inherited := make(map[string]DefaultValueChange)
+	for _, change := range result.Inherited {
+		inherited[change.Path] = change
+	}
+	masked := make(map[string]DefaultValueChange)
+	for _, change := range result.Masked {
+		masked[change.Path] = change
+	}
+
+	require.Len(t, result.Inherited, 4)
+	assert.Equal(t, DefaultValueChange{Path: "replicas", Op: OpReplace, Before: 1, After: 2}, inherited["replicas"])
+	assert.Equal(t, DefaultValueChange{Path: "legacy", Op: OpRemove, Before: true}, inherited["legacy"])
+	assert.Equal(t, "y", inherited[`annotations.a\.b/c`].After)
+	// Only the keys of an added map that are not overridden are inherited
+	assert.Equal(t, DefaultValueChange{Path: "probes.liveness.path", Op: OpAdd, After: "/healthz"}, inherited["probes.liveness.path"])
+
+	require.Len(t, result.Masked, 3)
+	assert.Equal(t, "1.26", masked["image.tag"].Override)
+	assert.Equal(t, "none", masked["resources.limits.cpu"].Override)
+	assert.Equal(t, false, masked["probes.liveness.enabled"].Override)
+
+	assert.Empty(t, DiffDefaultValues(before, before, nil).Inherited)
+}
+
+func TestDiffResult_SetValues(t *testing.T) {
+	result := &DiffResult{}
+	result.SetValues(&ValuesDiff{})
+	assert.Nil(t, result.Values)
+
+	result.SetValues(DiffDefaultValues(
+		map[string]interface{}{"replicas": 1, "port": 80},
+		map[string]interface{}{"replicas": 2, "port": 8080},
+		map[string]interface{}{"port": 9090},
+	))
+	require.NotNil(t, result.Values)
+	assert.Contains(t, result.Raw, "=== Inherited Default Changes ===\n  replicas [replace]\n    - 1\n    + 2\n")
+	assert.Contains(t, result.Raw, "  port [replace]\n    - 80\n    + 8080\n    = 9090 (supplied)\n")
+}
@@ -0,0 +1,194 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Values warning types
+const (
+	WarningOrphanedValue = "orphaned-value" // Supplied value for a key the new chart no longer has
+	WarningLikelyRename  = "likely-rename"  // Orphaned value whose key appears to have been renamed
+	WarningSchema        = "schema"         // values.schema.json validation error
+)
+
+// CheckSuppliedValues reports supplied values that set a key of the old chart's
+// defaults which the new chart's defaults no longer have. If exactly one key added
+// in the new defaults holds the same default value, preferring keys close to the
+// old one, the warning names it as a likely rename. Keys unknown to the old chart
+// and keys under maps that are empty by default are free-form and not reported.
+func CheckSuppliedValues(before, after, supplied map[string]interface{}) []ValuesWarning {
+	var added []defaultValue
+	walkAddedDefaults(nil, before, after, func(path []string, value interface{}) {
+		added = append(added, defaultValue{path: path, value: value})
+	})
+
+	var warnings []ValuesWarning
+	walkOrphanedValues(nil, supplied, before, after, func(path []string, oldDefault interface{}) {
+		warning := ValuesWarning{
+			Type:    WarningOrphanedValue,
+			Path:    valuesPath(path),
+			Message: fmt.Sprintf("%s is set but no longer exists in the new chart's default values", valuesPath(path)),
+		}
+		if target := likelyRename(path, oldDefault, added); target != nil {
+			warning.Type = WarningLikelyRename
+			warning.RenamedTo = valuesPath(target)
+			warning.Message = fmt.Sprintf("%s is set but was likely renamed to %s", valuesPath(path), valuesPath(target))
+		}
+		warnings = append(warnings, warning)
+	})
+	return warnings
+}
+
+// defaultValue is a key of a chart's default values
+type defaultValue struct {
+	path  []string
+	value interface{}
+}
+
+// walkOrphanedValues visits supplied keys present in the old defaults but missing
+// from the new ones, with their old default value. Only the topmost missing key is visited.
+func walkOrphanedValues(path []string, supplied, before, after interface{}, visit func(path []string, oldDefault interface{})) {
+	s, ok := supplied.(map[string]interface{})
+	if !ok {
+		return
+	}
+	b, ok := before.(map[string]interface{})
+	if !ok || len(b) == 0 {
+		return
+	}
+	a, ok := after.(map[string]interface{})
+	if ok && len(a) == 0 {
+		return
+	}
+
+	for _, k := range sortedKeys(s) {
+		oldDefault, inBefore := b[k]
+		if !inBefore {
+			continue
+		}
+		childPath := append(append([]string{}, path...), k)
+		newDefault, inAfter := a[k]
+		if !inAfter {
+			visit(childPath, oldDefault)
+			continue
+		}
+		walkOrphanedValues(childPath, s[k], oldDefault, newDefault, visit)
+	}
+}
+
+// walkAddedDefaults visits the keys present in the new defaults but not the old
+// ones, including the keys nested in added maps
+func walkAddedDefaults(path []string, before, after interface{}, visit func(path []string, value interface{})) {
+	b, ok1 := before.(map[string]interface{})
+	a, ok2 := after.(map[string]interface{})
+	if !ok1 || !ok2 || len(b) == 0 {
+		return
+	}
+	for _, k := range sortedKeys(a) {
+		childPath := append(append([]string{}, path...), k)
+		if v, ok := b[k]; ok {
+			walkAddedDefaults(childPath, v, a[k], visit)
+		} else {
+			walkNodes(childPath, a[k], visit)
+		}
+	}
+}
+
+// walkNodes visits a values tree and every key nested in it
+func walkNodes(path []string, value interface{}, visit func(path []string, value interface{})) {
+	visit(path, value)
+	if m, ok := value.(map[string]interface{}); ok {
+		for _, k := range sortedKeys(m) {
+			walkNodes(append(append([]string{}, path...), k), m[k], visit)
+		}
+	}
+}
+
+// likelyRename picks the added key holding the old default value that shares the
+// longest prefix with path, then the same last key. Returns nil if there is no
+// such key, if it is ambiguous or if the old default is empty.
+func likelyRename(path []string, oldDefault interface{}, added []defaultValue) []string {
+	if isEmptyValue(oldDefault) {
+		return nil
+	}
+
+	var best []string
+	bestScore, tied := -1, false
+	for _, candidate := range added {
+		if !reflect.DeepEqual(candidate.value, oldDefault) {
+			continue
+		}
+		score := 2 * commonPrefixLen(path, candidate.path)
+		if candidate.path[len(candidate.path)-1] == path[len(path)-1] {
+			score++
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = candidate.path, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if tied {
+		return nil
+	}
+	return best
+}
+
+// commonPrefixLen returns the number of leading keys two paths share
+func commonPrefixLen(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// isEmptyValue reports whether a default value is too unspecific to match on
+func isEmptyValue(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case map[string]interface{}:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a values map in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AddWarnings records values warnings and appends them to the raw output
+func (r *DiffResult) AddWarnings(warnings ...ValuesWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+	r.Warnings = append(r.Warnings, warnings...)
+
+	var sb strings.Builder
+	sb.WriteString("=== Values Warnings ===\n")
+	for _, w := range warnings {
+		if w.Side != "" {
+			sb.WriteString(fmt.Sprintf("  [%s] (%s) %s\n", w.Type, w.Side, w.Message))
+		} else {
+			sb.WriteString(fmt.Sprintf("  [%s] %s\n", w.Type, w.Message))
+		}
+	}
+	sb.WriteString("\n")
+
+	r.Raw += sb.String()
+}
@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSuppliedValues(t *testing.T) {
+	before := map[string]interface{}{
+		"replicas":       1,
+		"image":          map[string]interface{}{"repository": "nginx", "tag": "1.25"},
+		"logLevel":       "info",
+		"legacy":         map[string]interface{}{"enabled": true},
+		"podAnnotations": map[string]interface{}{},
+		"service":        map[string]interface{}{"port": 80, "type": "ClusterIP"},
+	}
+	after := map[string]interface{}{
+		"replicas":       1,
+		"image":          map[string]interface{}{"repository": "nginx", "version": "1.25"},
+		"logging":        map[string]interface{}{"level": "info"},
+		"podAnnotations": map[string]interface{}{},
+		"service":        map[string]interface{}{},
+	}
+	supplied := map[string]interface{}{
+		"replicas":       3,
+		"image":          map[string]interface{}{"tag": "1.26"},
+		"logLevel":       "debug",
+		"legacy":         map[string]interface{}{"enabled": false},
+		"podAnnotations": map[string]interface{}{"a": "b"},
+		"service":        map[string]interface{}{"port": 8080},
+		"unknown":        true,
+	}
+
+	warnings := CheckSuppliedValues(before, after, supplied)
+	require.Len(t, warnings, 3)
+
+	assert.Equal(t, WarningLikelyRename, warnings[0].Type)
+	assert.Equal(t, "image.tag", warnings[0].Path)
+	assert.Equal(t, "image.version", warnings[0].RenamedTo)
+
+	assert.Equal(t, WarningOrphanedValue, warnings[1].Type)
+	assert.Equal(t, "legacy", warnings[1].Path)
+	assert.Empty(t, warnings[1].RenamedTo)
+
+	assert.Equal(t, WarningLikelyRename, warnings[2].Type)
+	assert.Equal(t, "logLevel", warnings[2].Path)
+	assert.Equal(t, "logging.level", warnings[2].RenamedTo)
+}
+
+func TestCheckSuppliedValues_AmbiguousRename(t *testing.T) {
+	before := map[string]interface{}{"enabled": true}
+	after := map[string]interface{}{"metrics": true, "tracing": true}
+
+	warnings := CheckSuppliedValues(before, after, map[string]interface{}{"enabled": false})
+	require.Len(t, warnings, 1)
+	assert.Equal(t, WarningOrphanedValue, warnings[0].Type)
+	assert.Empty(t, warnings[0].RenamedTo)
+}
+
+func TestAddWarnings(t *testing.T) {
+	result := &DiffResult{}
+	result.AddWarnings()
+	assert.Empty(t, result.Raw)
+
+	result.AddWarnings(
+		ValuesWarning{Type: WarningOrphanedValue, Path: "legacy", Message: "legacy is set but no longer exists in the new chart's default values"},
+		ValuesWarning{Type: WarningSchema, Side: "right", Path: "replicas", Message: "demo: replicas: Invalid type. Expected: integer, given: string"},
+	)
+	require.Len(t, result.Warnings, 2)
+	assert.Contains(t, result.Raw, "=== Values Warnings ===")
+	assert.Contains(t, result.Raw, "[orphaned-value] legacy is set")
+	assert.Contains(t, result.Raw, "[schema] (right) demo: replicas")
+}
@@ -2,10 +2,11 @@ package models
 
 // CompareRequest represents a request to compare two Helm chart versions
 type CompareRequest struct {
-	Repository     string   `json:"repository"`               // Git repository URL (required)
-	ChartPath      string   `json:"chartPath"`                // Path to chart within repository (required)
-	Version1       string   `json:"version1"`                 // First version to compare (tag/branch/commit)
-	Version2       string   `json:"version2"`                 // Second version to compare (tag/branch/commit)
+	Repository     string   `json:"repository"`               // Git repository URL, Helm repository URL, oci:// reference or local directory (required)
+	ChartPath      string   `json:"chartPath"`                // Path to chart within Git repository, or chart name for packaged sources (required)
+	SourceType     string   `json:"sourceType,omitempty"`     // Optional: git (default)|helm-repo|oci|tarball
+	Version1       string   `json:"version1"`                 // First version to compare (tag/branch/commit, or chart version)
+	Version2       string   `json:"version2"`                 // Second version to compare (tag/branch/commit, or chart version)
 	ValuesFile     *string  `json:"valuesFile,omitempty"`     // Optional: path to values file in repository
 	ValuesContent  *string  `json:"valuesContent,omitempty"`  // Optional: inline values content
 	IgnoreLabels   bool     `json:"ignoreLabels,omitempty"`   // Optional: ignore label changes in diff
@@ -13,6 +14,55 @@ type CompareRequest struct {
 	ContextLines   *int     `json:"contextLines,omitempty"`   // Optional: number of context lines in diff
 	SuppressKinds  []string `json:"suppressKinds,omitempty"`  // Optional: resource kinds to suppress
 	SuppressRegex  *string  `json:"suppressRegex,omitempty"`  // Optional: regex pattern to suppress
+	IncludeObjects bool     `json:"includeObjects,omitempty"` // Optional: include full objects of added and removed resources
+
+	// Layered values, applied like `helm template -f ... --set ...`
+	ValuesFiles []string         `json:"valuesFiles,omitempty"` // Optional: ordered values files in repository, applied after valuesFile
+	Set         []string         `json:"set,omitempty"`         // Optional: --set style overrides (key=value)
+	SetString   []string         `json:"setString,omitempty"`   // Optional: --set-string style overrides (key=value)
+	SetFile     []string         `json:"setFile,omitempty"`     // Optional: --set-file style overrides (key=path in repository)
+	LeftValues  *ValuesOverrides `json:"leftValues,omitempty"`  // Optional: values applied to version1 only, on top of the shared ones
+	RightValues *ValuesOverrides `json:"rightValues,omitempty"` // Optional: values applied to version2 only, on top of the shared ones
+
+	// Values-only comparison: a single version rendered with leftValues and rightValues
+	Version string `json:"version,omitempty"` // Version to render on both sides (tag/branch/commit)
+
+	// Cross-source comparison: per-side sources, overriding the shared repository, chartPath and sourceType
+	Left  *SourceDescriptor `json:"left,omitempty"`  // Optional: source of version1
+	Right *SourceDescriptor `json:"right,omitempty"` // Optional: source of version2
+}
+
+// SourceDescriptor describes where one side of a comparison comes from.
+// Empty fields fall back to the shared request fields.
+type SourceDescriptor struct {
+	Repository string `json:"repository,omitempty"` // Git repository URL, Helm repository URL, oci:// reference or local directory
+	ChartPath  string `json:"chartPath,omitempty"`  // Path to chart within Git repository, or chart name for packaged sources
+	Version    string `json:"version,omitempty"`    // Tag/branch/commit, or chart version; overrides version1/version2
+	SourceType string `json:"sourceType,omitempty"` // git|helm-repo|oci|tarball
+}
+
+// ValuesOverrides describes values applied to only one side of a comparison
+type ValuesOverrides struct {
+	ValuesFiles   []string `json:"valuesFiles,omitempty"`   // Ordered values files in repository
+	ValuesContent *string  `json:"valuesContent,omitempty"` // Inline values content, applied after valuesFiles
+	Set           []string `json:"set,omitempty"`           // --set style overrides
+	SetString     []string `json:"setString,omitempty"`     // --set-string style overrides
+	SetFile       []string `json:"setFile,omitempty"`       // --set-file style overrides (paths in repository)
+}
+
+// ManifestDiffRequest represents a request to diff two rendered manifest bundles directly,
+// e.g. the output of `kustomize build` or `kubectl get -o yaml`
+type ManifestDiffRequest struct {
+	Manifest1      string   `json:"manifest1"`                // Left multi-document YAML bundle (required)
+	Manifest2      string   `json:"manifest2"`                // Right multi-document YAML bundle (required)
+	Name1          string   `json:"name1,omitempty"`          // Optional: label for the left bundle, e.g. a file name
+	Name2          string   `json:"name2,omitempty"`          // Optional: label for the right bundle, e.g. a file name
+	IgnoreLabels   bool     `json:"ignoreLabels,omitempty"`   // Optional: ignore label changes in diff
+	SecretHandling string   `json:"secretHandling,omitempty"` // Optional: suppress|show|decode
+	SuppressKinds  []string `json:"suppressKinds,omitempty"`  // Optional: resource kinds to suppress
+	SuppressRegex  *string  `json:"suppressRegex,omitempty"`  // Optional: regex pattern to suppress
+	IncludeObjects bool     `json:"includeObjects,omitempty"` // Optional: include full objects of added and removed resources
+	ContextLines   *int     `json:"contextLines,omitempty"`   // Optional: context lines around multi-line string changes
 }
 
 // CompareResponse represents the response from a chart comparison
@@ -25,6 +75,7 @@ type CompareResponse struct {
 	Statistics              *ChangeStatistics     `json:"statistics,omitempty"`     // Optional: statistics about changes (legacy)
 	StructuredDiff          *StructuredDiffResult `json:"structuredDiff,omitempty"` // v1 structured diff result
 	StructuredDiffAvailable bool                  `json:"structuredDiffAvailable"`  // Indicates if structured diff is available
+	Warnings                []ValuesWarning       `json:"warnings,omitempty"`       // Problems with the supplied values
 }
 
 // ChangeStatistics provides detailed statistics about the changes between versions
@@ -97,9 +148,37 @@ type BreakingChange struct {
 // StructuredDiffResult is an alias for the diff engine's DiffResult
 // This is exposed in the API response for frontend consumption
 type StructuredDiffResult struct {
-	Metadata  DiffMetadata   `json:"metadata"`
-	Resources []ResourceDiff `json:"resources"`
-	Stats     *DiffStats     `json:"stats,omitempty"`
+	Metadata          DiffMetadata       `json:"metadata"`
+	Resources         []ResourceDiff     `json:"resources"`
+	Stats             *DiffStats         `json:"stats,omitempty"`
+	Artifacts         []ArtifactDiff     `json:"artifacts,omitempty"`
+	Chart             *ChartDiff         `json:"chart,omitempty"`
+	Values            *ValuesDiff        `json:"values,omitempty"`
+	Warnings          []ValuesWarning    `json:"warnings,omitempty"`
+	ParseWarnings     []ParseWarning     `json:"parseWarnings,omitempty"`
+	IdentityConflicts []IdentityConflict `json:"identityConflicts,omitempty"`
+}
+
+// IdentityConflict is a set of resources on one side that share an object identity:
+// duplicates, or the same kind, namespace and name under different apiVersions
+type IdentityConflict struct {
+	Side        string   `json:"side,omitempty"`
+	Type        string   `json:"type"`
+	Kind        string   `json:"kind"`
+	Name        string   `json:"name"`
+	Namespace   string   `json:"namespace,omitempty"`
+	APIVersions []string `json:"apiVersions"`
+	Count       int      `json:"count"`
+	Sources     []string `json:"sources,omitempty"`
+	Message     string   `json:"message"`
+}
+
+// ParseWarning describes a manifest document that was skipped or only partly used
+type ParseWarning struct {
+	Side     string `json:"side,omitempty"`
+	Document int    `json:"document"`
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
 }
 
 // DiffMetadata provides traceability and context
@@ -109,6 +188,7 @@ type DiffMetadata struct {
 	GeneratedAt        string        `json:"generatedAt"`
 	Inputs             InputMetadata `json:"inputs"`
 	NormalizationRules []string      `json:"normalizationRules,omitempty"`
+	ComparisonMode     string        `json:"comparisonMode,omitempty"` // "values" for a values-only diff
 }
 
 // InputMetadata describes the sources being compared
@@ -120,6 +200,7 @@ type InputMetadata struct {
 // SourceMetadata describes a single input source
 type SourceMetadata struct {
 	Source     string `json:"source"`
+	Repository string `json:"repository,omitempty"`
 	Chart      string `json:"chart,omitempty"`
 	Version    string `json:"version,omitempty"`
 	ValuesHash string `json:"valuesHash,omitempty"`
@@ -127,6 +208,16 @@ type SourceMetadata struct {
 
 // DiffStats provides aggregate statistics
 type DiffStats struct {
+	Resources  DiffStatsResources   `json:"resources"`
+	Changes    DiffStatsChanges     `json:"changes"`
+	Suppressed *DiffStatsSuppressed `json:"suppressed,omitempty"`
+	BySubchart []SubchartStats      `json:"bySubchart,omitempty"`
+}
+
+// SubchartStats provides statistics for the resources rendered by one (sub)chart
+type SubchartStats struct {
+	Subchart  string             `json:"subchart"` // Empty for the parent chart
+	Chart     string             `json:"chart"`
 	Resources DiffStatsResources `json:"resources"`
 	Changes   DiffStatsChanges   `json:"changes"`
 }
@@ -136,6 +227,8 @@ type DiffStatsResources struct {
 	Added    int `json:"added"`
 	Removed  int `json:"removed"`
 	Modified int `json:"modified"`
+	Renamed  int `json:"renamed,omitempty"`
+	Moved    int `json:"moved,omitempty"`
 }
 
 // DiffStatsChanges provides change-level statistics
@@ -143,6 +236,12 @@ type DiffStatsChanges struct {
 	Total int `json:"total"`
 }
 
+// DiffStatsSuppressed reports what was filtered out by suppression rules
+type DiffStatsSuppressed struct {
+	Resources int `json:"resources"`
+	Changes   int `json:"changes"`
+}
+
 // ResourceDiff represents a diff for a single resource
 type ResourceDiff struct {
 	Identity   ResourceIdentity `json:"identity"`
@@ -151,6 +250,91 @@ type ResourceDiff struct {
 	AfterHash  string           `json:"afterHash,omitempty"`
 	Changes    []Change         `json:"changes,omitempty"`
 	Summary    *ResourceSummary `json:"summary,omitempty"`
+	Hook       *HookInfo        `json:"hook,omitempty"`
+	Source     *ResourceSource  `json:"source,omitempty"`
+
+	// Full normalized object of an added or removed resource, if requested
+	Object map[string]interface{} `json:"object,omitempty"`
+
+	// Set for renamed and moved resources, which Kubernetes deletes and recreates
+	PreviousIdentity *ResourceIdentity `json:"previousIdentity,omitempty"`
+	Similarity       float64           `json:"similarity,omitempty"`
+	Recreate         bool              `json:"recreate,omitempty"`
+}
+
+// ResourceSource is the template and (sub)chart a resource was rendered from
+type ResourceSource struct {
+	Template string `json:"template"`
+	Chart    string `json:"chart"`
+	Subchart string `json:"subchart,omitempty"`
+}
+
+// HookInfo describes a Helm hook resource
+type HookInfo struct {
+	Events         []string `json:"events"`
+	Weight         int      `json:"weight"`
+	DeletePolicies []string `json:"deletePolicies,omitempty"`
+}
+
+// ArtifactDiff is a line diff of a non-resource text output, e.g. NOTES.txt
+type ArtifactDiff struct {
+	Name       string `json:"name"`
+	ChangeType string `json:"changeType"`
+	Before     string `json:"before,omitempty"`
+	After      string `json:"after,omitempty"`
+	Diff       string `json:"diff"`
+}
+
+// ChartDiff describes Chart.yaml metadata and subchart changes
+type ChartDiff struct {
+	Changes      []ChartFieldChange `json:"changes,omitempty"`
+	Dependencies []DependencyChange `json:"dependencies,omitempty"`
+}
+
+// ChartFieldChange is a changed Chart.yaml field
+type ChartFieldChange struct {
+	Field       string      `json:"field"`
+	Before      interface{} `json:"before,omitempty"`
+	After       interface{} `json:"after,omitempty"`
+	VersionBump string      `json:"versionBump,omitempty"` // major|minor|patch|prerelease
+	Downgrade   bool        `json:"downgrade,omitempty"`
+}
+
+// DependencyChange is an added, removed or changed subchart
+type DependencyChange struct {
+	Name             string `json:"name"`
+	ChangeType       string `json:"changeType"`
+	BeforeVersion    string `json:"beforeVersion,omitempty"`
+	AfterVersion     string `json:"afterVersion,omitempty"`
+	BeforeRepository string `json:"beforeRepository,omitempty"`
+	AfterRepository  string `json:"afterRepository,omitempty"`
+	VersionBump      string `json:"versionBump,omitempty"` // major|minor|patch|prerelease
+	Downgrade        bool   `json:"downgrade,omitempty"`
+}
+
+// ValuesDiff splits chart default value changes into inherited and masked ones
+type ValuesDiff struct {
+	Inherited []DefaultValueChange `json:"inherited,omitempty"` // Reach the rendered output
+	Masked    []DefaultValueChange `json:"masked,omitempty"`    // Hidden by supplied values
+}
+
+// DefaultValueChange is a changed key in the chart's default values
+type DefaultValueChange struct {
+	Path     string      `json:"path"`
+	Op       string      `json:"op"`
+	Before   interface{} `json:"before,omitempty"`
+	After    interface{} `json:"after,omitempty"`
+	Override interface{} `json:"override,omitempty"`
+}
+
+// ValuesWarning is a problem with the supplied values: an override of a key the new
+// chart no longer has, possibly renamed, or a values.schema.json violation
+type ValuesWarning struct {
+	Type      string `json:"type"`
+	Side      string `json:"side,omitempty"`
+	Path      string `json:"path,omitempty"`
+	RenamedTo string `json:"renamedTo,omitempty"`
+	Message   string `json:"message"`
 }
 
 // ResourceIdentity uniquely identifies a resource
@@ -171,16 +355,65 @@ type ResourceSummary struct {
 
 // Change represents a field-level change
 type Change struct {
-	Op             string        `json:"op"`
-	Path           string        `json:"path"`
-	PathTokens     []interface{} `json:"pathTokens"`
-	Before         interface{}   `json:"before,omitempty"`
-	After          interface{}   `json:"after,omitempty"`
-	ValueType      string        `json:"valueType"`
-	SemanticType   string        `json:"semanticType,omitempty"`
-	ChangeCategory string        `json:"changeCategory,omitempty"`
-	Importance     string        `json:"importance,omitempty"`
-	Flags          []string      `json:"flags,omitempty"`
+	Op         string        `json:"op"`
+	Path       string        `json:"path"`
+	PathTokens []interface{} `json:"pathTokens"`
+	Pointer    string        `json:"pointer"` // RFC 6901 JSON Pointer
+
+	// RFC 6901 JSON Pointer into a document embedded in the string at Pointer
+	EmbeddedPointer string         `json:"embeddedPointer,omitempty"`
+	Before          interface{}    `json:"before,omitempty"`
+	After           interface{}    `json:"after,omitempty"`
+	ValueType       string         `json:"valueType"`
+	SemanticType    string         `json:"semanticType,omitempty"`
+	ChangeCategory  string         `json:"changeCategory,omitempty"`
+	Importance      string         `json:"importance,omitempty"`
+	Flags           []string       `json:"flags,omitempty"`
+	ArrayDiff       *ArrayDiff     `json:"arrayDiff,omitempty"`
+	QuantityDelta   *QuantityDelta `json:"quantityDelta,omitempty"`
+	Image           *ImageChange   `json:"image,omitempty"`
+	Hunks           []TextHunk     `json:"hunks,omitempty"` // Line diff of multi-line strings
+}
+
+// TextHunk is one hunk of a unified line diff
+type TextHunk struct {
+	OldStart int      `json:"oldStart"`
+	OldLines int      `json:"oldLines"`
+	NewStart int      `json:"newStart"`
+	NewLines int      `json:"newLines"`
+	Lines    []string `json:"lines"` // Prefixed with " ", "-" or "+"
+}
+
+// ImageReference is a container image reference split into its parts
+type ImageReference struct {
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+}
+
+// ImageChange classifies a change between two image references
+type ImageChange struct {
+	Before      ImageReference `json:"before"`
+	After       ImageReference `json:"after"`
+	Kinds       []string       `json:"kinds"`                 // registry|repository|tag|digest|digest-pinned|digest-unpinned
+	VersionBump string         `json:"versionBump,omitempty"` // major|minor|patch|prerelease
+	Downgrade   bool           `json:"downgrade,omitempty"`
+}
+
+// QuantityDelta is the numeric difference between two resource quantities
+type QuantityDelta struct {
+	Delta string  `json:"delta"` // e.g. "+250m" or "-512Mi"
+	Value float64 `json:"value"` // In base units, e.g. cores or bytes
+}
+
+// ArrayDiff describes how list elements were matched and which changed
+type ArrayDiff struct {
+	Strategy string        `json:"strategy"` // "indexed" or "keyed"
+	Key      string        `json:"key,omitempty"`
+	Added    []interface{} `json:"added,omitempty"`
+	Removed  []interface{} `json:"removed,omitempty"`
+	Modified []interface{} `json:"modified,omitempty"`
 }
 
 // VersionsRequest represents a request to fetch available versions from a repository
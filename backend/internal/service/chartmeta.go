@@ -0,0 +1,208 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+
+	"github.com/dcotelo/chartimpact/backend/internal/diff"
+)
+
+// chartMetadataField reads one Chart.yaml field; empty values are returned as nil
+type chartMetadataField struct {
+	name    string
+	version bool // Classify changes as semver bumps
+	get     func(m *chart.Metadata) interface{}
+}
+
+// chartMetadataFields lists the Chart.yaml fields compared, in output order
+var chartMetadataFields = []chartMetadataField{
+	{name: "apiVersion", get: func(m *chart.Metadata) interface{} { return nonEmpty(m.APIVersion) }},
+	{name: "name", get: func(m *chart.Metadata) interface{} { return nonEmpty(m.Name) }},
+	{name: "version", version: true, get: func(m *chart.Metadata) interface{} { return nonEmpty(m.Version) }},
+	{name: "appVersion", version: true, get: func(m *chart.Metadata) interface{} { return nonEmpty(m.AppVersion) }},
+	{name: "kubeVersion", get: func(m *chart.Metadata) interface{} { return nonEmpty(m.KubeVersion) }},
+	{name: "type", get: func(m *chart.Metadata) interface{} {
+		// Charts without a type are application charts
+		if m.Type == "" {
+			return "application"
+		}
+		return m.Type
+	}},
+	{name: "deprecated", get: func(m *chart.Metadata) interface{} {
+		if !m.Deprecated {
+			return nil
+		}
+		return true
+	}},
+	{name: "description", get: func(m *chart.Metadata) interface{} { return nonEmpty(m.Description) }},
+	{name: "home", get: func(m *chart.Metadata) interface{} { return nonEmpty(m.Home) }},
+	{name: "icon", get: func(m *chart.Metadata) interface{} { return nonEmpty(m.Icon) }},
+	{name: "sources", get: func(m *chart.Metadata) interface{} { return nonEmptyList(m.Sources) }},
+	{name: "keywords", get: func(m *chart.Metadata) interface{} { return nonEmptyList(m.Keywords) }},
+	{name: "maintainers", get: func(m *chart.Metadata) interface{} {
+		if len(m.Maintainers) == 0 {
+			return nil
+		}
+		return m.Maintainers
+	}},
+	{name: "annotations", get: func(m *chart.Metadata) interface{} {
+		if len(m.Annotations) == 0 {
+			return nil
+		}
+		return m.Annotations
+	}},
+}
+
+// diffChartMetadata compares Chart.yaml and Chart.lock of two fetched charts.
+// It must run before dependencies are built, since that rewrites Chart.lock.
+// Returns nil for kustomizations or if either chart cannot be loaded.
+func (h *HelmService) diffChartMetadata(chart1, chart2 *FetchedChart) *diff.ChartDiff {
+	if chart1.Kustomization || chart2.Kustomization {
+		return nil
+	}
+
+	before, err := loader.Load(chart1.ChartDir)
+	if err != nil {
+		log.Warnf("Failed to load chart metadata for version 1: %v", err)
+		return nil
+	}
+	after, err := loader.Load(chart2.ChartDir)
+	if err != nil {
+		log.Warnf("Failed to load chart metadata for version 2: %v", err)
+		return nil
+	}
+	return compareChartMetadata(before, after)
+}
+
+// compareChartMetadata lists changed Chart.yaml fields and subchart changes
+func compareChartMetadata(before, after *chart.Chart) *diff.ChartDiff {
+	result := &diff.ChartDiff{}
+	if before.Metadata == nil || after.Metadata == nil {
+		return result
+	}
+
+	for _, field := range chartMetadataFields {
+		v1, v2 := field.get(before.Metadata), field.get(after.Metadata)
+		if reflect.DeepEqual(v1, v2) {
+			continue
+		}
+		change := diff.ChartFieldChange{Field: field.name, Before: v1, After: v2}
+		if field.version {
+			s1, _ := v1.(string)
+			s2, _ := v2.(string)
+			change.VersionBump, change.Downgrade = diff.ClassifyVersionChange(s1, s2)
+		}
+		result.Changes = append(result.Changes, change)
+	}
+
+	deps1, deps2 := chartDependencies(before), chartDependencies(after)
+	names := make([]string, 0, len(deps1)+len(deps2))
+	for name := range deps1 {
+		names = append(names, name)
+	}
+	for name := range deps2 {
+		if _, ok := deps1[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		d1, ok1 := deps1[name]
+		d2, ok2 := deps2[name]
+		change := diff.DependencyChange{
+			Name:             name,
+			BeforeVersion:    d1.version,
+			AfterVersion:     d2.version,
+			BeforeRepository: d1.repository,
+			AfterRepository:  d2.repository,
+		}
+		switch {
+		case !ok1:
+			change.ChangeType = diff.ChangeTypeAdded
+		case !ok2:
+			change.ChangeType = diff.ChangeTypeRemoved
+		case d1 != d2:
+			change.ChangeType = diff.ChangeTypeModified
+			change.VersionBump, change.Downgrade = diff.ClassifyVersionChange(d1.version, d2.version)
+		default:
+			continue
+		}
+		result.Dependencies = append(result.Dependencies, change)
+	}
+
+	return result
+}
+
+// chartDependency is the resolved version and repository of a subchart
+type chartDependency struct {
+	version    string
+	repository string
+}
+
+// chartDependencies returns the subcharts of a chart keyed by alias or name.
+// Versions come from Chart.lock when present, otherwise from the Chart.yaml
+// constraint; subcharts vendored in charts/ without an entry are included too.
+func chartDependencies(c *chart.Chart) map[string]chartDependency {
+	deps := make(map[string]chartDependency)
+
+	locked := make(map[string]string)
+	if c.Lock != nil {
+		for _, dep := range c.Lock.Dependencies {
+			locked[dep.Name+"\x00"+dep.Repository] = dep.Version
+		}
+	}
+
+	for _, dep := range c.Metadata.Dependencies {
+		name := dep.Name
+		if dep.Alias != "" {
+			name = dep.Alias
+		}
+		version := dep.Version
+		if v, ok := locked[dep.Name+"\x00"+dep.Repository]; ok {
+			version = v
+		}
+		deps[name] = chartDependency{version: version, repository: dep.Repository}
+	}
+
+	for _, sub := range c.Dependencies() {
+		if sub.Metadata == nil {
+			continue
+		}
+		if _, ok := deps[sub.Name()]; !ok && !isDeclaredDependency(c, sub.Name()) {
+			deps[sub.Name()] = chartDependency{version: sub.Metadata.Version}
+		}
+	}
+
+	return deps
+}
+
+// isDeclaredDependency reports whether a chart name is declared in Chart.yaml, possibly under an alias
+func isDeclaredDependency(c *chart.Chart, name string) bool {
+	for _, dep := range c.Metadata.Dependencies {
+		if dep.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// nonEmpty returns nil for an empty string
+func nonEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// nonEmptyList returns nil for an empty list
+func nonEmptyList(items []string) interface{} {
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
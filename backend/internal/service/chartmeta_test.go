@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/dcotelo/chartimpact/backend/internal/diff"
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+func TestCompareChartMetadata(t *testing.T) {
+	before := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "app",
+			Version:    "1.4.2",
+			AppVersion: "2.7.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "redis", Version: "~17.0.0", Repository: "https://charts.bitnami.com/bitnami"},
+				{Name: "common", Version: "1.x", Repository: "https://charts.example.com"},
+			},
+		},
+		Lock: &chart.Lock{Dependencies: []*chart.Dependency{
+			{Name: "redis", Version: "17.0.11", Repository: "https://charts.bitnami.com/bitnami"},
+			{Name: "common", Version: "1.2.0", Repository: "https://charts.example.com"},
+		}},
+	}
+	after := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion:  "v2",
+			Name:        "app",
+			Version:     "2.0.0",
+			AppVersion:  "2.7.1",
+			KubeVersion: ">=1.25.0-0",
+			Type:        "application",
+			Maintainers: []*chart.Maintainer{{Name: "platform-team"}},
+			Dependencies: []*chart.Dependency{
+				{Name: "redis", Version: "~18.1.0", Repository: "https://charts.bitnami.com/bitnami"},
+				{Name: "postgresql", Alias: "db", Version: "12.5.6", Repository: "https://charts.bitnami.com/bitnami"},
+			},
+		},
+		Lock: &chart.Lock{Dependencies: []*chart.Dependency{
+			{Name: "redis", Version: "18.1.3", Repository: "https://charts.bitnami.com/bitnami"},
+			{Name: "postgresql", Version: "12.5.6", Repository: "https://charts.bitnami.com/bitnami"},
+		}},
+	}
+
+	result := compareChartMetadata(before, after)
+
+	fields := make(map[string]diff.ChartFieldChange)
+	for _, change := range result.Changes {
+		fields[change.Field] = change
+	}
+	// A missing type means application, so it is not a change
+	assert.NotContains(t, fields, "type")
+	assert.Equal(t, diff.VersionBumpMajor, fields["version"].VersionBump)
+	assert.Equal(t, diff.VersionBumpPatch, fields["appVersion"].VersionBump)
+	assert.Nil(t, fields["kubeVersion"].Before)
+	assert.Equal(t, ">=1.25.0-0", fields["kubeVersion"].After)
+	assert.Contains(t, fields, "maintainers")
+
+	require.Len(t, result.Dependencies, 3)
+	assert.Equal(t, diff.DependencyChange{
+		Name: "common", ChangeType: diff.ChangeTypeRemoved,
+		BeforeVersion: "1.2.0", BeforeRepository: "https://charts.example.com",
+	}, result.Dependencies[0])
+	assert.Equal(t, "db", result.Dependencies[1].Name)
+	assert.Equal(t, diff.ChangeTypeAdded, result.Dependencies[1].ChangeType)
+	assert.Equal(t, "redis", result.Dependencies[2].Name)
+	assert.Equal(t, diff.ChangeTypeModified, result.Dependencies[2].ChangeType)
+	assert.Equal(t, "17.0.11", result.Dependencies[2].BeforeVersion)
+	assert.Equal(t, "18.1.3", result.Dependencies[2].AfterVersion)
+	assert.Equal(t, diff.VersionBumpMajor, result.Dependencies[2].VersionBump)
+}
+
+func TestCompareVersions_ChartMetadata(t *testing.T) {
+	chartFiles := func(chartYaml string) map[string]string {
+		return map[string]string{
+			"chart/Chart.yaml":               chartYaml,
+			"chart/values.yaml":              "replicas: 1\nlogLevel: info\nregion: us\n",
+			"chart/templates/configmap.yaml": testConfigMapTemplate,
+		}
+	}
+	repo := newTestChartRepo(t, []string{"v1", "v2"}, map[string]map[string]string{
+		"v1": chartFiles("apiVersion: v2\nname: demo\nversion: 0.1.0\nappVersion: \"1.0.0\"\n"),
+		"v2": chartFiles("apiVersion: v2\nname: demo\nversion: 0.2.0\nappVersion: \"1.1.0\"\ndeprecated: true\n"),
+	})
+	service := newTestHelmService(t)
+
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "chart",
+		Version1:   "v1",
+		Version2:   "v2",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success, resp.Error)
+	require.NotNil(t, resp.StructuredDiff.Chart)
+
+	fields := make(map[string]models.ChartFieldChange)
+	for _, change := range resp.StructuredDiff.Chart.Changes {
+		fields[change.Field] = change
+	}
+	assert.Equal(t, "minor", fields["version"].VersionBump)
+	assert.Equal(t, "1.1.0", fields["appVersion"].After)
+	assert.Equal(t, true, fields["deprecated"].After)
+	assert.Contains(t, resp.Diff, "=== Chart ===")
+	assert.Contains(t, resp.Diff, "appVersion (minor)")
+}
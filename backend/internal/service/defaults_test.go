@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+func TestCompareVersions_InheritedDefaults(t *testing.T) {
+	chart := func(values string) map[string]string {
+		return map[string]string{
+			"chart/Chart.yaml":               testChartYaml,
+			"chart/values.yaml":              values,
+			"chart/templates/configmap.yaml": testConfigMapTemplate,
+		}
+	}
+	repo := newTestChartRepo(t, []string{"v1", "v2"}, map[string]map[string]string{
+		"v1": chart("replicas: 1\nlogLevel: info\nregion: us\n"),
+		"v2": chart("replicas: 2\nlogLevel: debug\nregion: us\n"),
+	})
+	service := newTestHelmService(t)
+
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "chart",
+		Version1:   "v1",
+		Version2:   "v2",
+		Set:        []string{"logLevel=warn"},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success, resp.Error)
+
+	values := resp.StructuredDiff.Values
+	require.NotNil(t, values)
+	require.Len(t, values.Inherited, 1)
+	assert.Equal(t, models.DefaultValueChange{Path: "replicas", Op: "replace", Before: float64(1), After: float64(2)}, values.Inherited[0])
+	require.Len(t, values.Masked, 1)
+	assert.Equal(t, "logLevel", values.Masked[0].Path)
+	assert.Equal(t, "warn", values.Masked[0].Override)
+
+	// The masked default does not show up in the rendered diff
+	assert.NotNil(t, findTestChange(resp.StructuredDiff, "data.replicas"))
+	assert.Nil(t, findTestChange(resp.StructuredDiff, "data.logLevel"))
+	assert.Contains(t, resp.Diff, "=== Inherited Default Changes ===")
+}
@@ -18,7 +18,7 @@ import (
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
-	"sigs.k8s.io/yaml"
+	"helm.sh/helm/v3/pkg/cli/values"
 
 	"github.com/dcotelo/chartimpact/backend/internal/diff"
 	"github.com/dcotelo/chartimpact/backend/internal/models"
@@ -32,6 +32,7 @@ var excessiveNewlinesRegex = regexp.MustCompile(`\n{3,}`)
 type HelmService struct {
 	settings *cli.EnvSettings
 	tempDir  string
+	fetchers map[string]FetcherFactory
 }
 
 // NewHelmService creates a new instance of HelmService
@@ -47,17 +48,19 @@ func NewHelmService() *HelmService {
 		log.Warnf("Failed to create temp directory %s: %v", tempDir, err)
 	}
 
-	return &HelmService{
+	h := &HelmService{
 		settings: settings,
 		tempDir:  tempDir,
 	}
+	h.fetchers = h.defaultFetchers()
+	return h
 }
 
 // CompareVersions compares two versions of a Helm chart and returns the diff
 // This is the main method that orchestrates the entire comparison process:
 // 1. Creates a unique work directory
-// 2. Clones the Git repository
-// 3. Extracts both chart versions
+// 2. Opens the chart source (Git clone, Helm repository index, OCI registry or local tarballs)
+// 3. Fetches both chart versions
 // 4. Builds dependencies for both versions
 // 5. Renders templates using Helm SDK
 // 6. Compares rendered manifests using the internal comparison engine
@@ -72,51 +75,70 @@ func (h *HelmService) CompareVersions(ctx context.Context, req *models.CompareRe
 	}
 	defer h.cleanup(workDir)
 
+	left, right := ResolveSources(req)
 	log.WithFields(log.Fields{
-		"repository": req.Repository,
-		"chartPath":  req.ChartPath,
-		"version1":   req.Version1,
-		"version2":   req.Version2,
-		"workDir":    workDir,
+		"repository1": left.Repository,
+		"chartPath1":  left.ChartPath,
+		"sourceType1": left.SourceType,
+		"version1":    left.Version,
+		"repository2": right.Repository,
+		"chartPath2":  right.ChartPath,
+		"sourceType2": right.SourceType,
+		"version2":    right.Version,
+		"workDir":     workDir,
 	}).Info("Starting chart comparison")
 
-	// Clone the repository
-	repoDir := filepath.Join(workDir, "repo")
-	if err := h.cloneRepository(ctx, req.Repository, repoDir); err != nil {
+	// Open the chart sources
+	fetcher1, fetcher2, err := h.openFetchers(ctx, left, right, workDir)
+	if err != nil {
 		return &models.CompareResponse{
 			Success: false,
-			Error:   fmt.Sprintf("Failed to clone repository: %v", err),
+			Error:   fmt.Sprintf("Failed to open chart source: %v", err),
 		}, nil
 	}
 
-	// Extract version 1
-	chart1Dir := filepath.Join(workDir, "version1")
-	if err := h.extractVersion(ctx, repoDir, req.ChartPath, req.Version1, chart1Dir, req.ValuesFile, req.ValuesContent); err != nil {
+	// Fetch version 1 and resolve its values before fetching version 2,
+	// so values files are read from the same version as the chart
+	chart1, err := fetcher1.Fetch(ctx, left.Version, filepath.Join(workDir, "version1"))
+	if err != nil {
 		return &models.CompareResponse{
 			Success: false,
-			Error:   fmt.Sprintf("Failed to extract version 1 (%s): %v", req.Version1, err),
+			Error:   fmt.Sprintf("Failed to extract version 1 (%s): %v", left.Version, err),
 		}, nil
 	}
-
-	// Extract version 2
-	chart2Dir := filepath.Join(workDir, "version2")
-	if err := h.extractVersion(ctx, repoDir, req.ChartPath, req.Version2, chart2Dir, req.ValuesFile, req.ValuesContent); err != nil {
+	values1, err := h.resolveValues(chart1.ValuesRoot, left.Version, filepath.Join(workDir, "values1"), buildValuesPlan(req, req.LeftValues))
+	if err != nil {
 		return &models.CompareResponse{
 			Success: false,
-			Error:   fmt.Sprintf("Failed to extract version 2 (%s): %v", req.Version2, err),
+			Error:   fmt.Sprintf("Failed to resolve values for version 1 (%s): %v", left.Version, err),
 		}, nil
 	}
 
-	// Build dependencies for both versions
-	if err := h.buildDependencies(ctx, chart1Dir); err != nil {
-		log.Warnf("Failed to build dependencies for version 1: %v", err)
+	// Fetch version 2
+	chart2, err := fetcher2.Fetch(ctx, right.Version, filepath.Join(workDir, "version2"))
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to extract version 2 (%s): %v", right.Version, err),
+		}, nil
 	}
-	if err := h.buildDependencies(ctx, chart2Dir); err != nil {
-		log.Warnf("Failed to build dependencies for version 2: %v", err)
+	values2, err := h.resolveValues(chart2.ValuesRoot, right.Version, filepath.Join(workDir, "values2"), buildValuesPlan(req, req.RightValues))
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to resolve values for version 2 (%s): %v", right.Version, err),
+		}, nil
 	}
 
+	// Build dependencies for both versions
+	// Compare Chart.yaml and Chart.lock before building dependencies rewrites the lock
+	chartDiff := h.diffChartMetadata(chart1, chart2)
+
+	h.prepareDependencies(ctx, chart1, "version 1")
+	h.prepareDependencies(ctx, chart2, "version 2")
+
 	// Render templates for both versions using Helm SDK
-	rendered1, err := h.renderTemplate(ctx, chart1Dir, req.ValuesContent)
+	rendered1, err := h.renderFetched(ctx, chart1, values1)
 	if err != nil {
 		return &models.CompareResponse{
 			Success: false,
@@ -124,7 +146,7 @@ func (h *HelmService) CompareVersions(ctx context.Context, req *models.CompareRe
 		}, nil
 	}
 
-	rendered2, err := h.renderTemplate(ctx, chart2Dir, req.ValuesContent)
+	rendered2, err := h.renderFetched(ctx, chart2, values2)
 	if err != nil {
 		return &models.CompareResponse{
 			Success: false,
@@ -133,7 +155,7 @@ func (h *HelmService) CompareVersions(ctx context.Context, req *models.CompareRe
 	}
 
 	// Compare the rendered templates
-	diffResult, diffRaw, err := h.compareRendered(ctx, rendered1, rendered2, req.IgnoreLabels)
+	diffResult, diffRaw, err := h.compareReleases(ctx, rendered1, rendered2, req)
 	if err != nil {
 		return &models.CompareResponse{
 			Success: false,
@@ -141,12 +163,143 @@ func (h *HelmService) CompareVersions(ctx context.Context, req *models.CompareRe
 		}, nil
 	}
 
+	// Record chart metadata changes and which values were applied to each side
+	if diffResult != nil {
+		diffResult.SetChart(chartDiff)
+		diffRaw = diffResult.Raw
+	}
+	setValuesHashes(diffResult, values1, values2)
+
 	log.Info("Chart comparison completed successfully")
 
-	response := h.buildCompareResponse(req.Version1, req.Version2, diffRaw, diffResult)
+	response := h.buildCompareResponse(left.Version, right.Version, diffRaw, diffResult)
 	return response, nil
 }
 
+// CompareValues renders a single chart version twice, once with the left values
+// and once with the right values, and diffs the results. Only one checkout and
+// dependency build are needed since the chart itself is identical on both sides.
+// Requests with per-side Left/Right sources are rejected rather than ignored.
+func (h *HelmService) CompareValues(ctx context.Context, req *models.CompareRequest) (*models.CompareResponse, error) {
+	if req.Left != nil || req.Right != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   "left and right sources are not supported for values comparisons",
+		}, nil
+	}
+
+	workDir, err := h.createWorkDir()
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to create work directory: %v", err),
+		}, nil
+	}
+	defer h.cleanup(workDir)
+
+	log.WithFields(log.Fields{
+		"repository": req.Repository,
+		"chartPath":  req.ChartPath,
+		"version":    req.Version,
+		"workDir":    workDir,
+	}).Info("Starting values comparison")
+
+	source, _ := ResolveSources(req)
+	fetcher, err := h.newFetcher(ctx, chartSource(source), workDir)
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to open chart source: %v", err),
+		}, nil
+	}
+
+	fetched, err := fetcher.Fetch(ctx, req.Version, filepath.Join(workDir, "chart"))
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to extract version %s: %v", req.Version, err),
+		}, nil
+	}
+
+	// Both values sets are resolved from the same fetched version
+	leftValues, err := h.resolveValues(fetched.ValuesRoot, req.Version, filepath.Join(workDir, "values1"), buildValuesPlan(req, req.LeftValues))
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to resolve left values: %v", err),
+		}, nil
+	}
+	rightValues, err := h.resolveValues(fetched.ValuesRoot, req.Version, filepath.Join(workDir, "values2"), buildValuesPlan(req, req.RightValues))
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to resolve right values: %v", err),
+		}, nil
+	}
+
+	h.prepareDependencies(ctx, fetched, "chart")
+
+	rendered1, err := h.renderFetched(ctx, fetched, leftValues)
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to render templates with left values: %v", err),
+		}, nil
+	}
+
+	rendered2, err := h.renderFetched(ctx, fetched, rightValues)
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to render templates with right values: %v", err),
+		}, nil
+	}
+
+	diffResult, diffRaw, err := h.compareReleases(ctx, rendered1, rendered2, req)
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to compare templates: %v", err),
+		}, nil
+	}
+
+	setValuesHashes(diffResult, leftValues, rightValues)
+	if diffResult != nil {
+		diffResult.Metadata.ComparisonMode = diff.ComparisonModeValues
+	}
+
+	log.Info("Values comparison completed successfully")
+
+	return h.buildCompareResponse(req.Version, req.Version, diffRaw, diffResult), nil
+}
+
+// prepareDependencies builds dependencies for charts fetched from source.
+// Packaged charts already include their dependencies and kustomizations
+// inflate their own charts, so both are left untouched.
+func (h *HelmService) prepareDependencies(ctx context.Context, chart *FetchedChart, label string) {
+	if chart.Packaged || chart.Kustomization {
+		return
+	}
+	if err := h.buildDependencies(ctx, chart.ChartDir); err != nil {
+		log.Warnf("Failed to build dependencies for %s: %v", label, err)
+	}
+}
+
+// setValuesHashes records which values were applied to each side of a diff
+func setValuesHashes(diffResult *diff.DiffResult, left, right *values.Options) {
+	if diffResult == nil {
+		return
+	}
+
+	var err error
+	if diffResult.Metadata.Inputs.Left.ValuesHash, err = computeValuesHash(left); err != nil {
+		log.Warnf("Failed to hash left values: %v", err)
+	}
+	if diffResult.Metadata.Inputs.Right.ValuesHash, err = computeValuesHash(right); err != nil {
+		log.Warnf("Failed to hash right values: %v", err)
+	}
+}
+
 // buildCompareResponse constructs a CompareResponse with structured diff if available
 func (h *HelmService) buildCompareResponse(version1, version2, diffRaw string, diffResult *diff.DiffResult) *models.CompareResponse {
 	response := &models.CompareResponse{
@@ -160,6 +313,7 @@ func (h *HelmService) buildCompareResponse(version1, version2, diffRaw string, d
 	if diffResult != nil {
 		response.StructuredDiff = h.convertToStructuredDiff(diffResult)
 		response.StructuredDiffAvailable = true
+		response.Warnings = response.StructuredDiff.Warnings
 	} else {
 		response.StructuredDiffAvailable = false
 	}
@@ -224,19 +378,11 @@ func (h *HelmService) cloneRepository(ctx context.Context, repoURL, destDir stri
 // extractVersion checks out a specific version and copies the chart to a destination directory
 // Supports Git tags, branches, and commit SHAs
 // Validates chart structure (Chart.yaml and templates/ directory)
-func (h *HelmService) extractVersion(ctx context.Context, repoDir, chartPath, version, destDir string, valuesFile, valuesContent *string) error {
+func (h *HelmService) extractVersion(ctx context.Context, repoDir, chartPath, version, destDir string) error {
 	log.Infof("Extracting version %s from chart path %s", version, chartPath)
 
-	// Fetch all refs to ensure we have the version
-	fetchCmd := exec.CommandContext(ctx, "git", "-C", repoDir, "fetch", "--all", "--tags", "--prune")
-	if output, err := fetchCmd.CombinedOutput(); err != nil {
-		log.Warnf("Failed to fetch all refs: %v\nOutput: %s", err, string(output))
-	}
-
-	// Checkout the specified version
-	checkoutCmd := exec.CommandContext(ctx, "git", "-C", repoDir, "checkout", version)
-	if output, err := checkoutCmd.CombinedOutput(); err != nil {
-		return util.WrapCommandError(fmt.Sprintf("checkout version %s", version), err, output)
+	if err := h.checkoutVersion(ctx, repoDir, version); err != nil {
+		return err
 	}
 
 	// Validate chart path exists and has proper structure
@@ -257,19 +403,26 @@ func (h *HelmService) extractVersion(ctx context.Context, repoDir, chartPath, ve
 	}
 
 	// Copy chart directory to destination
-	if err := h.copyDir(sourceChartPath, destDir); err != nil {
+	if err := h.copyDir(sourceChartPath, destDir, repoDir); err != nil {
 		return fmt.Errorf("failed to copy chart: %w", err)
 	}
 
-	// Handle values file if specified
-	if valuesFile != nil && *valuesFile != "" {
-		sourceValuesPath := filepath.Join(repoDir, *valuesFile)
-		destValuesPath := filepath.Join(destDir, "custom-values.yaml")
-		if err := h.copyFile(sourceValuesPath, destValuesPath); err != nil {
-			log.Warnf("Failed to copy values file: %v", err)
-		}
+	return nil
+}
+
+// checkoutVersion fetches all refs and checks out a tag, branch or commit SHA
+func (h *HelmService) checkoutVersion(ctx context.Context, repoDir, version string) error {
+	// Fetch all refs to ensure we have the version
+	fetchCmd := exec.CommandContext(ctx, "git", "-C", repoDir, "fetch", "--all", "--tags", "--prune")
+	if output, err := fetchCmd.CombinedOutput(); err != nil {
+		log.Warnf("Failed to fetch all refs: %v\nOutput: %s", err, string(output))
 	}
 
+	// Checkout the specified version
+	checkoutCmd := exec.CommandContext(ctx, "git", "-C", repoDir, "checkout", version)
+	if output, err := checkoutCmd.CombinedOutput(); err != nil {
+		return util.WrapCommandError(fmt.Sprintf("checkout version %s", version), err, output)
+	}
 	return nil
 }
 
@@ -375,16 +528,28 @@ func (h *HelmService) generateRepoName(repoURL string) string {
 	return fmt.Sprintf("repo-%x", []byte(repoURL)[:8])
 }
 
+// renderedRelease is the output of rendering one side of a comparison
+type renderedRelease struct {
+	Manifest string // Multi-document YAML, including hooks and test resources
+	Notes    string // Rendered NOTES.txt, empty if the chart has none
+
+	// Chart defaults, including subcharts, and the supplied values; nil for kustomizations
+	Defaults map[string]interface{}
+	Values   map[string]interface{}
+
+	SchemaWarnings []diff.ValuesWarning // values.schema.json violations of the supplied values
+}
+
 // renderTemplate renders a Helm chart to YAML using the Helm Go SDK
 // Uses action.Install with DryRun=true for client-side rendering
-// Supports custom values via valuesContent parameter
-func (h *HelmService) renderTemplate(ctx context.Context, chartDir string, valuesContent *string) (string, error) {
+// User values are merged like `helm template -f ... --set ...` on top of the chart defaults
+func (h *HelmService) renderTemplate(ctx context.Context, chartDir string, valueOpts *values.Options) (*renderedRelease, error) {
 	log.Infof("Rendering chart at %s", chartDir)
 
 	// Create Helm action configuration
 	actionConfig := new(action.Configuration)
 	if err := actionConfig.Init(h.settings.RESTClientGetter(), h.settings.Namespace(), os.Getenv("HELM_DRIVER"), log.Debugf); err != nil {
-		return "", fmt.Errorf("failed to initialize Helm action config: %w", err)
+		return nil, fmt.Errorf("failed to initialize Helm action config: %w", err)
 	}
 
 	// Create install action (dry-run for templating)
@@ -407,42 +572,103 @@ func (h *HelmService) renderTemplate(ctx context.Context, chartDir string, value
 	// Load the chart
 	chart, err := loader.Load(chartDir)
 	if err != nil {
-		return "", fmt.Errorf("failed to load chart: %w", err)
+		return nil, fmt.Errorf("failed to load chart: %w", err)
 	}
 
-	// Parse custom values if provided
-	vals := map[string]interface{}{}
-	if valuesContent != nil && *valuesContent != "" {
-		if err := yaml.Unmarshal([]byte(*valuesContent), &vals); err != nil {
-			return "", fmt.Errorf("failed to parse values content: %w", err)
-		}
+	// Merge user-supplied values
+	vals, err := h.mergeValues(valueOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Coalesce the chart and subchart defaults without user values
+	defaults, err := chartutil.CoalesceValues(chart, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart default values: %w", err)
+	}
+
+	// Report schema violations as warnings rather than failing the render
+	schemaWarnings, err := validateSchemas(chart, vals)
+	if err != nil {
+		return nil, err
 	}
 
 	// Run the install (dry-run)
 	rel, err := client.Run(chart, vals)
 	if err != nil {
-		return "", fmt.Errorf("failed to render chart: %w", err)
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+
+	// Hooks, including test resources, are not part of rel.Manifest;
+	// append them like `helm template` does so they are diffed too
+	var manifest strings.Builder
+	manifest.WriteString(rel.Manifest)
+	for _, hook := range rel.Hooks {
+		fmt.Fprintf(&manifest, "\n---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
 	}
 
-	return rel.Manifest, nil
+	rendered := &renderedRelease{Manifest: manifest.String(), Defaults: defaults, Values: vals, SchemaWarnings: schemaWarnings}
+	if rel.Info != nil {
+		rendered.Notes = rel.Info.Notes
+	}
+	return rendered, nil
+}
+
+// compareReleases compares two rendered releases: their manifests, including
+// hooks, their NOTES.txt as a text artifact and their chart default values.
+// Problems with the supplied values are recorded as warnings.
+func (h *HelmService) compareReleases(ctx context.Context, rendered1, rendered2 *renderedRelease, req *models.CompareRequest) (*diff.DiffResult, string, error) {
+	diffResult, diffRaw, err := h.compareRendered(ctx, rendered1.Manifest, rendered2.Manifest, req)
+	if err != nil || diffResult == nil {
+		return diffResult, diffRaw, err
+	}
+
+	contextLines := diff.DefaultContextLines
+	if req.ContextLines != nil {
+		contextLines = *req.ContextLines
+	}
+	diffResult.AddArtifact(diff.DiffText("NOTES.txt", rendered1.Notes, rendered2.Notes, contextLines))
+
+	// Default values changes reach the new version unless its supplied values override them
+	if rendered1.Defaults != nil && rendered2.Defaults != nil {
+		diffResult.SetValues(diff.DiffDefaultValues(rendered1.Defaults, rendered2.Defaults, rendered2.Values))
+	}
+
+	var warnings []diff.ValuesWarning
+	if rendered1.Defaults != nil && rendered2.Defaults != nil {
+		warnings = diff.CheckSuppliedValues(rendered1.Defaults, rendered2.Defaults, rendered2.Values)
+	}
+	for _, side := range []struct {
+		name     string
+		rendered *renderedRelease
+	}{{"left", rendered1}, {"right", rendered2}} {
+		for _, w := range side.rendered.SchemaWarnings {
+			w.Side = side.name
+			warnings = append(warnings, w)
+		}
+	}
+	diffResult.AddWarnings(warnings...)
+	return diffResult, diffResult.Raw, nil
 }
 
 // compareRendered compares two rendered YAML manifests
 // Returns the structured diff result, raw string output, and any error
 // Uses the internal diff engine as the primary comparison mechanism
-// If ignoreLabels is true, filters out metadata.labels and metadata.annotations changes
+// Diff options (ignoreLabels, suppression rules) are taken from the request
 //
 // DEPRECATED: The dyff and simple diff fallback paths are deprecated and will be removed in a future version.
 // The internal diff engine is now the recommended and default comparison mechanism.
-func (h *HelmService) compareRendered(ctx context.Context, rendered1, rendered2 string, ignoreLabels bool) (*diff.DiffResult, string, error) {
+func (h *HelmService) compareRendered(ctx context.Context, rendered1, rendered2 string, req *models.CompareRequest) (*diff.DiffResult, string, error) {
 	log.Info("Comparing rendered templates")
+	ignoreLabels := req.IgnoreLabels
 
 	// Check if internal diff engine is enabled (default: true)
 	if util.GetBoolEnv("INTERNAL_DIFF_ENABLED", true) {
 		log.Info("Using internal diff engine")
-		diffEngine := diff.NewEngine()
-		diffEngine.IgnoreLabels = ignoreLabels
-		diffEngine.IgnoreAnnotations = ignoreLabels
+		diffEngine, err := newDiffEngine(req)
+		if err != nil {
+			return nil, "", err
+		}
 
 		result, err := diffEngine.Compare(rendered1, rendered2)
 		if err == nil {
@@ -469,6 +695,31 @@ func (h *HelmService) compareRendered(ctx context.Context, rendered1, rendered2
 	return nil, h.simpleDiff(rendered1, rendered2), nil
 }
 
+// newDiffEngine creates a diff engine configured from the comparison request
+func newDiffEngine(req *models.CompareRequest) (*diff.Engine, error) {
+	diffEngine := diff.NewEngine()
+	diffEngine.IgnoreLabels = req.IgnoreLabels
+	diffEngine.IgnoreAnnotations = req.IgnoreLabels
+	diffEngine.SuppressKinds = req.SuppressKinds
+	diffEngine.SecretHandling = req.SecretHandling
+	diffEngine.IncludeObjects = req.IncludeObjects
+	diffEngine.ContextLines = req.ContextLines
+
+	left, right := ResolveSources(req)
+	diffEngine.LeftSource = sourceMetadata(left)
+	diffEngine.RightSource = sourceMetadata(right)
+
+	if req.SuppressRegex != nil && *req.SuppressRegex != "" {
+		re, err := regexp.Compile(*req.SuppressRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid suppressRegex: %w", err)
+		}
+		diffEngine.SuppressRegex = re
+	}
+
+	return diffEngine, nil
+}
+
 // dyffCompare uses the dyff tool for enhanced YAML comparison
 // Creates temporary files and runs dyff between command
 // If ignoreLabels is true, filters out metadata.labels and metadata.annotations from the output
@@ -633,15 +884,18 @@ func (h *HelmService) convertToStructuredDiff(diffResult *diff.DiffResult) *mode
 			CompareID:          diffResult.Metadata.CompareID,
 			GeneratedAt:        diffResult.Metadata.GeneratedAt,
 			NormalizationRules: diffResult.Metadata.NormalizationRules,
+			ComparisonMode:     diffResult.Metadata.ComparisonMode,
 			Inputs: models.InputMetadata{
 				Left: models.SourceMetadata{
 					Source:     diffResult.Metadata.Inputs.Left.Source,
+					Repository: diffResult.Metadata.Inputs.Left.Repository,
 					Chart:      diffResult.Metadata.Inputs.Left.Chart,
 					Version:    diffResult.Metadata.Inputs.Left.Version,
 					ValuesHash: diffResult.Metadata.Inputs.Left.ValuesHash,
 				},
 				Right: models.SourceMetadata{
 					Source:     diffResult.Metadata.Inputs.Right.Source,
+					Repository: diffResult.Metadata.Inputs.Right.Repository,
 					Chart:      diffResult.Metadata.Inputs.Right.Chart,
 					Version:    diffResult.Metadata.Inputs.Right.Version,
 					ValuesHash: diffResult.Metadata.Inputs.Right.ValuesHash,
@@ -658,11 +912,33 @@ func (h *HelmService) convertToStructuredDiff(diffResult *diff.DiffResult) *mode
 				Added:    diffResult.Stats.Resources.Added,
 				Removed:  diffResult.Stats.Resources.Removed,
 				Modified: diffResult.Stats.Resources.Modified,
+				Renamed:  diffResult.Stats.Resources.Renamed,
+				Moved:    diffResult.Stats.Resources.Moved,
 			},
 			Changes: models.DiffStatsChanges{
 				Total: diffResult.Stats.Changes.Total,
 			},
 		}
+		for _, s := range diffResult.Stats.BySubchart {
+			result.Stats.BySubchart = append(result.Stats.BySubchart, models.SubchartStats{
+				Subchart: s.Subchart,
+				Chart:    s.Chart,
+				Resources: models.DiffStatsResources{
+					Added:    s.Resources.Added,
+					Removed:  s.Resources.Removed,
+					Modified: s.Resources.Modified,
+					Renamed:  s.Resources.Renamed,
+					Moved:    s.Resources.Moved,
+				},
+				Changes: models.DiffStatsChanges{Total: s.Changes.Total},
+			})
+		}
+		if diffResult.Stats.Suppressed != nil {
+			result.Stats.Suppressed = &models.DiffStatsSuppressed{
+				Resources: diffResult.Stats.Suppressed.Resources,
+				Changes:   diffResult.Stats.Suppressed.Changes,
+			}
+		}
 	}
 
 	// Convert resources
@@ -679,6 +955,33 @@ func (h *HelmService) convertToStructuredDiff(diffResult *diff.DiffResult) *mode
 			BeforeHash: r.BeforeHash,
 			AfterHash:  r.AfterHash,
 			Changes:    make([]models.Change, 0, len(r.Changes)),
+			Object:     r.Object,
+			Similarity: r.Similarity,
+			Recreate:   r.Recreate,
+		}
+		if p := r.PreviousIdentity; p != nil {
+			resource.PreviousIdentity = &models.ResourceIdentity{
+				APIVersion: p.APIVersion,
+				Kind:       p.Kind,
+				Name:       p.Name,
+				Namespace:  p.Namespace,
+				UID:        p.UID,
+			}
+		}
+		if r.Hook != nil {
+			resource.Hook = &models.HookInfo{
+				Events:         r.Hook.Events,
+				Weight:         r.Hook.Weight,
+				DeletePolicies: r.Hook.DeletePolicies,
+			}
+		}
+
+		if r.Source != nil {
+			resource.Source = &models.ResourceSource{
+				Template: r.Source.Template,
+				Chart:    r.Source.Chart,
+				Subchart: r.Source.Subchart,
+			}
 		}
 
 		// Convert summary if present
@@ -702,16 +1005,42 @@ func (h *HelmService) convertToStructuredDiff(diffResult *diff.DiffResult) *mode
 			}
 
 			change := models.Change{
-				Op:             string(c.Op),
-				Path:           c.Path,
-				PathTokens:     pathTokens,
-				Before:         c.Before,
-				After:          c.After,
-				ValueType:      c.ValueType,
-				SemanticType:   c.SemanticType,
-				ChangeCategory: c.ChangeCategory,
-				Importance:     c.Importance,
-				Flags:          c.Flags,
+				Op:              string(c.Op),
+				Path:            c.Path,
+				PathTokens:      pathTokens,
+				Pointer:         c.Pointer,
+				EmbeddedPointer: c.EmbeddedPointer,
+				Before:          c.Before,
+				After:           c.After,
+				ValueType:       c.ValueType,
+				SemanticType:    c.SemanticType,
+				ChangeCategory:  c.ChangeCategory,
+				Importance:      c.Importance,
+				Flags:           c.Flags,
+			}
+			if c.QuantityDelta != nil {
+				change.QuantityDelta = &models.QuantityDelta{Delta: c.QuantityDelta.Delta, Value: c.QuantityDelta.Value}
+			}
+			for _, hunk := range c.Hunks {
+				change.Hunks = append(change.Hunks, models.TextHunk(hunk))
+			}
+			if c.Image != nil {
+				change.Image = &models.ImageChange{
+					Before:      models.ImageReference(c.Image.Before),
+					After:       models.ImageReference(c.Image.After),
+					Kinds:       c.Image.Kinds,
+					VersionBump: c.Image.VersionBump,
+					Downgrade:   c.Image.Downgrade,
+				}
+			}
+			if c.ArrayDiff != nil {
+				change.ArrayDiff = &models.ArrayDiff{
+					Strategy: c.ArrayDiff.Strategy,
+					Key:      c.ArrayDiff.Key,
+					Added:    c.ArrayDiff.Added,
+					Removed:  c.ArrayDiff.Removed,
+					Modified: c.ArrayDiff.Modified,
+				}
 			}
 			resource.Changes = append(resource.Changes, change)
 		}
@@ -719,9 +1048,99 @@ func (h *HelmService) convertToStructuredDiff(diffResult *diff.DiffResult) *mode
 		result.Resources = append(result.Resources, resource)
 	}
 
+	if c := diffResult.Chart; c != nil {
+		result.Chart = &models.ChartDiff{}
+		for _, change := range c.Changes {
+			result.Chart.Changes = append(result.Chart.Changes, models.ChartFieldChange{
+				Field:       change.Field,
+				Before:      change.Before,
+				After:       change.After,
+				VersionBump: change.VersionBump,
+				Downgrade:   change.Downgrade,
+			})
+		}
+		for _, dep := range c.Dependencies {
+			result.Chart.Dependencies = append(result.Chart.Dependencies, models.DependencyChange{
+				Name:             dep.Name,
+				ChangeType:       string(dep.ChangeType),
+				BeforeVersion:    dep.BeforeVersion,
+				AfterVersion:     dep.AfterVersion,
+				BeforeRepository: dep.BeforeRepository,
+				AfterRepository:  dep.AfterRepository,
+				VersionBump:      dep.VersionBump,
+				Downgrade:        dep.Downgrade,
+			})
+		}
+	}
+
+	if v := diffResult.Values; v != nil {
+		result.Values = &models.ValuesDiff{
+			Inherited: convertDefaultValueChanges(v.Inherited),
+			Masked:    convertDefaultValueChanges(v.Masked),
+		}
+	}
+
+	for _, w := range diffResult.Warnings {
+		result.Warnings = append(result.Warnings, models.ValuesWarning{
+			Type:      w.Type,
+			Side:      w.Side,
+			Path:      w.Path,
+			RenamedTo: w.RenamedTo,
+			Message:   w.Message,
+		})
+	}
+
+	for _, w := range diffResult.ParseWarnings {
+		result.ParseWarnings = append(result.ParseWarnings, models.ParseWarning{
+			Side:     w.Side,
+			Document: w.Document,
+			Line:     w.Line,
+			Message:  w.Message,
+		})
+	}
+
+	for _, c := range diffResult.IdentityConflicts {
+		result.IdentityConflicts = append(result.IdentityConflicts, models.IdentityConflict{
+			Side:        c.Side,
+			Type:        c.Type,
+			Kind:        c.Kind,
+			Name:        c.Name,
+			Namespace:   c.Namespace,
+			APIVersions: c.APIVersions,
+			Count:       c.Count,
+			Sources:     c.Sources,
+			Message:     c.Message,
+		})
+	}
+
+	for _, a := range diffResult.Artifacts {
+		result.Artifacts = append(result.Artifacts, models.ArtifactDiff{
+			Name:       a.Name,
+			ChangeType: string(a.ChangeType),
+			Before:     a.Before,
+			After:      a.After,
+			Diff:       a.Diff,
+		})
+	}
+
 	return result
 }
 
+// convertDefaultValueChanges converts default value changes to the API model
+func convertDefaultValueChanges(changes []diff.DefaultValueChange) []models.DefaultValueChange {
+	converted := make([]models.DefaultValueChange, 0, len(changes))
+	for _, c := range changes {
+		converted = append(converted, models.DefaultValueChange{
+			Path:     c.Path,
+			Op:       string(c.Op),
+			Before:   c.Before,
+			After:    c.After,
+			Override: c.Override,
+		})
+	}
+	return converted
+}
+
 // suggestChartPath searches for Chart.yaml files and suggests valid chart paths
 // Helpful when user provides incorrect chart path
 func (h *HelmService) suggestChartPath(repoDir string) string {
@@ -744,8 +1163,10 @@ func (h *HelmService) suggestChartPath(repoDir string) string {
 	return strings.Join(suggestions, "\n")
 }
 
-// copyDir recursively copies a directory and all its contents
-func (h *HelmService) copyDir(src, dst string) error {
+// copyDir recursively copies a directory and all its contents. Symlinks are copied
+// as the files they point to, which must be inside repoDir, so a repository can't
+// pull files from elsewhere on the server into a build.
+func (h *HelmService) copyDir(src, dst, repoDir string) error {
 	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -757,7 +1178,11 @@ func (h *HelmService) copyDir(src, dst string) error {
 		}
 		targetPath := filepath.Join(dst, relPath)
 
-		if info.IsDir() {
+		if info.Mode()&os.ModeSymlink != 0 {
+			if path, err = repoSymlinkTarget(repoDir, path); err != nil {
+				return err
+			}
+		} else if info.IsDir() {
 			return os.MkdirAll(targetPath, info.Mode())
 		}
 
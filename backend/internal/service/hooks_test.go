@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+const testHookTemplate = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{ .Release.Name }}-migrate
+  annotations:
+    helm.sh/hook: pre-upgrade
+    helm.sh/hook-weight: {{ .Values.hookWeight | quote }}
+    helm.sh/hook-delete-policy: before-hook-creation
+spec:
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: migrate
+        image: {{ .Values.migrateImage }}
+`
+
+const testTestPodTemplate = `apiVersion: v1
+kind: Pod
+metadata:
+  name: {{ .Release.Name }}-test
+  annotations:
+    helm.sh/hook: test
+spec:
+  restartPolicy: Never
+  containers:
+  - name: curl
+    image: curlimages/curl:8.5.0
+`
+
+func TestCompareVersions_HooksAndNotes(t *testing.T) {
+	chart := func(values, notes string) map[string]string {
+		files := map[string]string{
+			"chart/Chart.yaml":               testChartYaml,
+			"chart/values.yaml":              values,
+			"chart/templates/configmap.yaml": testConfigMapTemplate,
+			"chart/templates/migrate.yaml":   testHookTemplate,
+			"chart/templates/NOTES.txt":      notes,
+		}
+		return files
+	}
+	v1 := chart("replicas: 1\nlogLevel: info\nregion: us\nhookWeight: 0\nmigrateImage: migrate:1.0\n",
+		"Thanks for installing.\nRun the migration first.\n")
+	v2 := chart("replicas: 1\nlogLevel: info\nregion: us\nhookWeight: 5\nmigrateImage: migrate:2.0\n",
+		"Thanks for installing.\nMigrations now run automatically.\n")
+	v2["chart/templates/tests/connection.yaml"] = testTestPodTemplate
+
+	repo := newTestChartRepo(t, []string{"v1", "v2"}, map[string]map[string]string{"v1": v1, "v2": v2})
+	service := newTestHelmService(t)
+
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "chart",
+		Version1:   "v1",
+		Version2:   "v2",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success, resp.Error)
+
+	byName := make(map[string]models.ResourceDiff)
+	for _, r := range resp.StructuredDiff.Resources {
+		byName[r.Identity.Name] = r
+	}
+
+	// Hook changes are diffed like any other resource
+	migrate, ok := byName["release-name-migrate"]
+	require.True(t, ok, "hook Job should be diffed")
+	require.NotNil(t, migrate.Hook)
+	assert.Equal(t, []string{"pre-upgrade"}, migrate.Hook.Events)
+	assert.Equal(t, 5, migrate.Hook.Weight)
+	assert.Equal(t, []string{"before-hook-creation"}, migrate.Hook.DeletePolicies)
+	change := findTestChange(resp.StructuredDiff, "spec.template.spec.containers[name=migrate].image")
+	require.NotNil(t, change)
+	assert.Equal(t, "migrate:2.0", change.After)
+
+	// Test resources are hooks on the test event
+	testPod, ok := byName["release-name-test"]
+	require.True(t, ok, "test Pod should be diffed")
+	assert.Equal(t, "added", testPod.ChangeType)
+	require.NotNil(t, testPod.Hook)
+	assert.Equal(t, []string{"test"}, testPod.Hook.Events)
+
+	require.Len(t, resp.StructuredDiff.Artifacts, 1)
+	notes := resp.StructuredDiff.Artifacts[0]
+	assert.Equal(t, "NOTES.txt", notes.Name)
+	assert.Equal(t, "modified", notes.ChangeType)
+	assert.Contains(t, notes.Diff, "-Run the migration first.\n+Migrations now run automatically.\n")
+	assert.Contains(t, resp.Diff, "--- NOTES.txt ---")
+}
@@ -30,7 +30,7 @@ metadata:
 data:
   key: value
 `
-		_, diffRaw, err := service.compareRendered(ctx, manifest, manifest, false)
+		_, diffRaw, err := service.compareRendered(ctx, manifest, manifest, &models.CompareRequest{})
 		assert.NoError(t, err)
 		assert.Contains(t, diffRaw, "Total Changes:      0")
 	})
@@ -53,7 +53,7 @@ kind: ConfigMap
 metadata:
   name: config2
 `
-		_, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, false)
+		_, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, &models.CompareRequest{})
 		assert.NoError(t, err)
 		assert.Contains(t, diffRaw, "Resources Added:    1")
 		assert.Contains(t, diffRaw, "config2")
@@ -76,7 +76,7 @@ metadata:
 data:
   key: value2
 `
-		_, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, false)
+		_, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, &models.CompareRequest{})
 		assert.NoError(t, err)
 		assert.Contains(t, diffRaw, "Resources Modified: 1")
 		assert.Contains(t, diffRaw, "data.key")
@@ -103,7 +103,7 @@ metadata:
 data:
   key: value
 `
-		_, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, true)
+		_, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, &models.CompareRequest{IgnoreLabels: true})
 		assert.NoError(t, err)
 		assert.Contains(t, diffRaw, "Total Changes:      0")
 	})
@@ -125,7 +125,7 @@ metadata:
   labels:
     version: v2
 `
-		_, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, false)
+		_, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, &models.CompareRequest{})
 		assert.NoError(t, err)
 		assert.Contains(t, diffRaw, "Resources Modified: 1")
 		assert.Contains(t, diffRaw, "metadata.labels.version")
@@ -163,7 +163,7 @@ data:
 
 	// DEPRECATED: Testing deprecated dyff fallback behavior
 	// This should use dyff or fall back to simple diff
-	_, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, false)
+	_, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, &models.CompareRequest{})
 	assert.NoError(t, err)
 	assert.NotEmpty(t, diffRaw)
 	// The exact format depends on whether dyff is available
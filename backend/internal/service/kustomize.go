@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// SourceTypeKustomize builds a kustomization directory in a Git repository at a tag, branch or commit
+const SourceTypeKustomize = "kustomize"
+
+// kustomizeFetcher checks out versions of a kustomization from a single clone of a Git repository
+type kustomizeFetcher struct {
+	h       *HelmService
+	repoDir string
+	path    string
+}
+
+// newKustomizeFetcher clones the repository once for all versions
+func (h *HelmService) newKustomizeFetcher(ctx context.Context, source ChartSource, workDir string) (ChartFetcher, error) {
+	repoDir := filepath.Join(workDir, "repo")
+	if err := h.cloneRepository(ctx, source.Repository, repoDir); err != nil {
+		return nil, fmt.Errorf("failed to clone repository: %w", err)
+	}
+	return &kustomizeFetcher{h: h, repoDir: repoDir, path: source.Chart}, nil
+}
+
+// Fetch checks out version and copies the whole repository, since overlays
+// usually refer to bases and charts elsewhere in the repository
+func (f *kustomizeFetcher) Fetch(ctx context.Context, version, destDir string) (*FetchedChart, error) {
+	if err := f.h.checkoutVersion(ctx, f.repoDir, version); err != nil {
+		return nil, err
+	}
+
+	kustomizationDir, err := repoFilePath(f.repoDir, f.path)
+	if err != nil {
+		return nil, err
+	}
+	if !hasKustomization(kustomizationDir) {
+		return nil, fmt.Errorf("kustomization not found at %s", f.path)
+	}
+
+	entries, err := os.ReadDir(f.repoDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.Name() == ".git" {
+			continue
+		}
+		if err := f.h.copyDir(filepath.Join(f.repoDir, entry.Name()), filepath.Join(destDir, entry.Name()), f.repoDir); err != nil {
+			return nil, fmt.Errorf("failed to copy repository: %w", err)
+		}
+	}
+
+	return &FetchedChart{
+		ChartDir:      filepath.Join(destDir, f.path),
+		ValuesRoot:    destDir,
+		Kustomization: true,
+	}, nil
+}
+
+// hasKustomization reports whether dir contains a kustomization file
+func hasKustomization(dir string) bool {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// renderKustomize builds a kustomization directory, like `kustomize build --enable-helm`.
+// helmCharts entries are inflated with the helm binary; charts found under the
+// kustomization's chartHome are used as-is, others are pulled from their repo.
+func (h *HelmService) renderKustomize(ctx context.Context, dir string) (*renderedRelease, error) {
+	log.Infof("Building kustomization at %s", dir)
+
+	opts := krusty.MakeDefaultOptions()
+	opts.PluginConfig = types.MakePluginConfig(types.PluginRestrictionsBuiltinsOnly, types.BploUseStaticallyLinked)
+	opts.PluginConfig.HelmConfig = types.HelmConfig{Enabled: true, Command: "helm"}
+
+	resources, err := krusty.MakeKustomizer(opts).Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("kustomize build failed: %w", err)
+	}
+	manifest, err := resources.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize kustomize output: %w", err)
+	}
+	return &renderedRelease{Manifest: string(manifest)}, nil
+}
+
+// renderFetched renders a fetched version with the renderer matching its source
+func (h *HelmService) renderFetched(ctx context.Context, chart *FetchedChart, valueOpts *values.Options) (*renderedRelease, error) {
+	if !chart.Kustomization {
+		return h.renderTemplate(ctx, chart.ChartDir, valueOpts)
+	}
+
+	// Kustomizations carry their own values in helmCharts entries
+	if valueOpts != nil && (len(valueOpts.ValueFiles) > 0 || len(valueOpts.Values) > 0 ||
+		len(valueOpts.StringValues) > 0 || len(valueOpts.FileValues) > 0) {
+		return nil, fmt.Errorf("values options are not supported for kustomize sources")
+	}
+	return h.renderKustomize(ctx, chart.ChartDir)
+}
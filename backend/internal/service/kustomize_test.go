@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+const testKustomizeBase = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+`
+
+// kustomizeOverlay returns a base plus production overlay patching the replica count
+func kustomizeOverlay(replicas string) map[string]string {
+	return map[string]string{
+		"base/kustomization.yaml": "resources:\n- deployment.yaml\n",
+		"base/deployment.yaml":    testKustomizeBase,
+		"overlays/prod/kustomization.yaml": `namespace: prod
+resources:
+- ../../base
+patches:
+- target:
+    kind: Deployment
+    name: web
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: ` + replicas + `
+`,
+	}
+}
+
+func TestCompareVersions_KustomizeSource(t *testing.T) {
+	repo := newTestChartRepo(t, []string{"v1", "v2"}, map[string]map[string]string{
+		"v1": kustomizeOverlay("2"),
+		"v2": kustomizeOverlay("3"),
+	})
+	service := newTestHelmService(t)
+
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "overlays/prod",
+		SourceType: SourceTypeKustomize,
+		Version1:   "v1",
+		Version2:   "v2",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success, resp.Error)
+	require.Len(t, resp.StructuredDiff.Resources, 1)
+	assert.Equal(t, "prod", resp.StructuredDiff.Resources[0].Identity.Namespace)
+
+	change := findTestChange(resp.StructuredDiff, "spec.replicas")
+	require.NotNil(t, change)
+	assert.EqualValues(t, 2, change.Before)
+	assert.EqualValues(t, 3, change.After)
+
+	inputs := resp.StructuredDiff.Metadata.Inputs
+	assert.Equal(t, SourceTypeKustomize, inputs.Left.Source)
+	assert.Equal(t, "overlays/prod", inputs.Left.Chart)
+	assert.Equal(t, "v2", inputs.Right.Version)
+
+	missing, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "overlays/staging",
+		SourceType: SourceTypeKustomize,
+		Version1:   "v1",
+		Version2:   "v2",
+	})
+	require.NoError(t, err)
+	assert.False(t, missing.Success)
+	assert.Contains(t, missing.Error, "kustomization not found at overlays/staging")
+
+	set, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "overlays/prod",
+		SourceType: SourceTypeKustomize,
+		Version1:   "v1",
+		Version2:   "v2",
+		Set:        []string{"replicas=3"},
+	})
+	require.NoError(t, err)
+	assert.False(t, set.Success)
+	assert.Contains(t, set.Error, "values options are not supported for kustomize sources")
+}
+
+func TestCompareVersions_KustomizeRejectsSymlinksOutsideRepo(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "leak.yaml")
+	require.NoError(t, os.WriteFile(outside, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: leak\ndata:\n  token: top-secret\n"), 0644))
+
+	repo := newTestChartRepo(t, []string{"v1"}, map[string]map[string]string{"v1": kustomizeOverlay("2")})
+	require.NoError(t, os.Symlink(outside, filepath.Join(repo, "base", "leak.yaml")))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "base", "kustomization.yaml"), []byte("resources:\n- deployment.yaml\n- leak.yaml\n"), 0644))
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", "v2"}, {"tag", "v2"}} {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	resp, err := newTestHelmService(t).CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "overlays/prod",
+		SourceType: SourceTypeKustomize,
+		Version1:   "v1",
+		Version2:   "v2",
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Error, "symlink base/leak.yaml points outside the repository")
+	assert.NotContains(t, resp.Error, "top-secret")
+}
+
+func TestCopyDir_Symlinks(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "chart", "files"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "shared.yaml"), []byte("shared"), 0644))
+	require.NoError(t, os.Symlink("../../shared.yaml", filepath.Join(repo, "chart", "files", "shared.yaml")))
+
+	// Links to files elsewhere in the repository are copied as those files
+	dest := filepath.Join(t.TempDir(), "chart")
+	require.NoError(t, NewHelmService().copyDir(filepath.Join(repo, "chart"), dest, repo))
+	content, err := os.ReadFile(filepath.Join(dest, "files", "shared.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "shared", string(content))
+	info, err := os.Lstat(filepath.Join(dest, "files", "shared.yaml"))
+	require.NoError(t, err)
+	assert.True(t, info.Mode().IsRegular())
+
+	for name, target := range map[string]string{
+		"outside":   filepath.Join(t.TempDir(), "passwd"),
+		"directory": "..",
+		"dangling":  "missing.yaml",
+	} {
+		t.Run(name, func(t *testing.T) {
+			chart := filepath.Join(repo, name)
+			require.NoError(t, os.MkdirAll(chart, 0755))
+			if name == "outside" {
+				require.NoError(t, os.WriteFile(target, []byte("root:x:0:0"), 0644))
+			}
+			require.NoError(t, os.Symlink(target, filepath.Join(chart, "link")))
+
+			err := NewHelmService().copyDir(chart, filepath.Join(t.TempDir(), name), repo)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "symlink "+name+"/link")
+		})
+	}
+}
+
+func TestCompareVersions_KustomizeHelmCharts(t *testing.T) {
+	if _, err := exec.LookPath("helm"); err != nil {
+		t.Skip("helm not available")
+	}
+
+	// The chart is vendored under the kustomization's chartHome, so nothing is pulled
+	inflating := func(region string) map[string]string {
+		return map[string]string{
+			"app/kustomization.yaml": `helmCharts:
+- name: demo
+  releaseName: demo
+  valuesInline:
+    replicas: 2
+    logLevel: info
+    region: ` + region + `
+`,
+			"app/charts/demo/Chart.yaml":               testChartYaml,
+			"app/charts/demo/values.yaml":              "replicas: 1\nlogLevel: info\nregion: us\n",
+			"app/charts/demo/templates/configmap.yaml": testConfigMapTemplate,
+		}
+	}
+	repo := newTestChartRepo(t, []string{"v1", "v2"}, map[string]map[string]string{
+		"v1": inflating("us"),
+		"v2": inflating("eu"),
+	})
+	service := newTestHelmService(t)
+
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "app",
+		SourceType: SourceTypeKustomize,
+		Version1:   "v1",
+		Version2:   "v2",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success, resp.Error)
+
+	change := findTestChange(resp.StructuredDiff, "data.region")
+	require.NotNil(t, change)
+	assert.Equal(t, "us", change.Before)
+	assert.Equal(t, "eu", change.After)
+	assert.Nil(t, findTestChange(resp.StructuredDiff, "data.replicas"))
+}
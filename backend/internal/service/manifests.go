@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dcotelo/chartimpact/backend/internal/diff"
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+// SourceManifests is the source reported for manifest bundles supplied directly by the caller
+const SourceManifests = "manifests"
+
+// CompareManifests diffs two rendered multi-document YAML bundles with the internal
+// diff engine. No repository is fetched and nothing is rendered, so any tool that
+// produces Kubernetes manifests can reuse the semantic classification.
+func (h *HelmService) CompareManifests(req *models.ManifestDiffRequest) (*models.CompareResponse, error) {
+	diffEngine, err := newDiffEngine(&models.CompareRequest{
+		IgnoreLabels:   req.IgnoreLabels,
+		SecretHandling: req.SecretHandling,
+		SuppressKinds:  req.SuppressKinds,
+		SuppressRegex:  req.SuppressRegex,
+		IncludeObjects: req.IncludeObjects,
+		ContextLines:   req.ContextLines,
+	})
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+	diffEngine.LeftSource = &diff.SourceMetadata{Source: SourceManifests, Version: req.Name1}
+	diffEngine.RightSource = &diff.SourceMetadata{Source: SourceManifests, Version: req.Name2}
+
+	diffResult, err := diffEngine.Compare(req.Manifest1, req.Manifest2)
+	if err != nil {
+		return &models.CompareResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to compare manifests: %v", err),
+		}, nil
+	}
+
+	log.Info("Manifest comparison completed successfully")
+
+	return h.buildCompareResponse(req.Name1, req.Name2, diffResult.Raw, diffResult), nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	"github.com/dcotelo/chartimpact/backend/internal/diff"
+)
+
+// validateSchemas validates values against values.schema.json of a chart and its
+// enabled subcharts, like Helm does before rendering, and returns one warning per
+// violation. The schemas are then removed from the chart so that a violation is
+// reported alongside the diff instead of failing the render.
+func validateSchemas(c *chart.Chart, vals map[string]interface{}) ([]diff.ValuesWarning, error) {
+	// Drop disabled subcharts first so their schemas are not applied
+	if err := chartutil.ProcessDependenciesWithMerge(c, vals); err != nil {
+		return nil, fmt.Errorf("failed to process chart dependencies: %w", err)
+	}
+	coalesced, err := chartutil.CoalesceValues(c, vals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to coalesce values: %w", err)
+	}
+
+	var warnings []diff.ValuesWarning
+	validateChartSchema(c, coalesced, nil, &warnings)
+	return warnings, nil
+}
+
+// validateChartSchema validates the values of one chart and recurses into its
+// subcharts, whose paths are prefixed with the subchart name
+func validateChartSchema(c *chart.Chart, vals map[string]interface{}, prefix []string, warnings *[]diff.ValuesWarning) {
+	if c.Schema != nil {
+		if err := chartutil.ValidateAgainstSingleSchema(vals, c.Schema); err != nil {
+			*warnings = append(*warnings, schemaWarnings(c.Name(), prefix, err)...)
+		}
+		c.Schema = nil
+	}
+
+	for _, sub := range c.Dependencies() {
+		subVals, _ := vals[sub.Name()].(map[string]interface{})
+		validateChartSchema(sub, subVals, append(append([]string{}, prefix...), sub.Name()), warnings)
+	}
+}
+
+// schemaWarnings splits a schema validation error into its "- field: description" lines
+func schemaWarnings(chartName string, prefix []string, err error) []diff.ValuesWarning {
+	var warnings []diff.ValuesWarning
+	for _, line := range strings.Split(strings.TrimSpace(err.Error()), "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "- ")
+		if line == "" {
+			continue
+		}
+
+		path := strings.Join(prefix, ".")
+		if field, _, ok := strings.Cut(line, ": "); ok && field != "(root)" && !strings.Contains(field, " ") {
+			if path != "" {
+				path += "."
+			}
+			path += field
+		}
+		warnings = append(warnings, diff.ValuesWarning{
+			Type:    diff.WarningSchema,
+			Path:    path,
+			Message: fmt.Sprintf("%s: %s", chartName, line),
+		})
+	}
+	return warnings
+}
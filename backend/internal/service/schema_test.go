@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+const testValuesSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "replicas": {"type": "integer", "minimum": 1}
+  }
+}`
+
+func TestCompareVersions_ValuesWarnings(t *testing.T) {
+	v1 := map[string]string{
+		"chart/Chart.yaml":               testChartYaml,
+		"chart/values.yaml":              "replicas: 1\nlogLevel: info\nregion: us\n",
+		"chart/templates/configmap.yaml": testConfigMapTemplate,
+	}
+	v2 := map[string]string{
+		"chart/Chart.yaml":         testChartYaml,
+		"chart/values.yaml":        "replicas: 1\nlogging:\n  level: info\nregion: us\n",
+		"chart/values.schema.json": testValuesSchema,
+		"chart/templates/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: demo
+data:
+  replicas: {{ .Values.replicas | quote }}
+  logLevel: {{ .Values.logging.level | quote }}
+  region: {{ .Values.region | quote }}
+`,
+	}
+	repo := newTestChartRepo(t, []string{"v1", "v2"}, map[string]map[string]string{"v1": v1, "v2": v2})
+	service := newTestHelmService(t)
+
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "chart",
+		Version1:   "v1",
+		Version2:   "v2",
+		Set:        []string{"logLevel=debug", "replicas=0"},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success, resp.Error)
+
+	// The schema violation is reported instead of failing the render
+	require.Len(t, resp.Warnings, 2)
+	assert.Equal(t, models.ValuesWarning{
+		Type:      "likely-rename",
+		Path:      "logLevel",
+		RenamedTo: "logging.level",
+		Message:   "logLevel is set but was likely renamed to logging.level",
+	}, resp.Warnings[0])
+	assert.Equal(t, "schema", resp.Warnings[1].Type)
+	assert.Equal(t, "right", resp.Warnings[1].Side)
+	assert.Equal(t, "replicas", resp.Warnings[1].Path)
+	assert.Contains(t, resp.Warnings[1].Message, "demo: replicas")
+
+	assert.Equal(t, resp.Warnings, resp.StructuredDiff.Warnings)
+	assert.Contains(t, resp.Diff, "=== Values Warnings ===")
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/dcotelo/chartimpact/backend/internal/diff"
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+// RedactSecrets returns a copy of the structured diff in which every Secret
+// data/stringData value, and every supplied value masking a default values
+// change, is replaced by a redaction marker.
+// It is applied before persisting results so that comparisons run with
+// secretHandling=show or decode never write secret values to storage.
+// The input is not modified, since it may still be serialized to the client.
+func RedactSecrets(result *models.StructuredDiffResult) *models.StructuredDiffResult {
+	if result == nil {
+		return nil
+	}
+
+	redacted := *result
+	redacted.Resources = make([]models.ResourceDiff, len(result.Resources))
+
+	for i, resource := range result.Resources {
+		redacted.Resources[i] = resource
+		if resource.Identity.Kind != "Secret" || resource.Identity.APIVersion != "v1" {
+			continue
+		}
+
+		if resource.Object != nil {
+			redacted.Resources[i].Object = redactSecretObject(resource.Object)
+		}
+
+		changes := make([]models.Change, len(resource.Changes))
+		for j, change := range resource.Changes {
+			if isSecretValuePath(change.PathTokens) {
+				change.Before = redactSecretValue(change.Before)
+				change.After = redactSecretValue(change.After)
+				change.Hunks = nil // Line diffs would reveal the values
+			}
+			changes[j] = change
+		}
+		redacted.Resources[i].Changes = changes
+	}
+
+	// Supplied values often hold passwords and tokens
+	if result.Values != nil {
+		values := *result.Values
+		values.Masked = make([]models.DefaultValueChange, len(result.Values.Masked))
+		for i, change := range result.Values.Masked {
+			change.Override = redactOverride(change.Override)
+			values.Masked[i] = change
+		}
+		redacted.Values = &values
+	}
+
+	return &redacted
+}
+
+// redactSecretObject returns a copy of a Secret object with its data and stringData values redacted
+func redactSecretObject(obj map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(obj))
+	for key, value := range obj {
+		if key == "data" || key == "stringData" {
+			value = redactSecretValue(value)
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// isSecretValuePath reports whether change path tokens point into Secret data or stringData
+func isSecretValuePath(tokens []interface{}) bool {
+	return len(tokens) > 0 && (tokens[0] == "data" || tokens[0] == "stringData")
+}
+
+// redactOverride redacts every scalar of a supplied value
+func redactOverride(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return diff.RedactValue(v)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			redacted[key] = redactOverride(item)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactOverride(item)
+		}
+		return redacted
+	default:
+		return diff.RedactValue(fmt.Sprint(v))
+	}
+}
+
+// redactSecretValue redacts a string value or every string inside a data map
+func redactSecretValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return diff.RedactValue(v)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			redacted[key] = redactSecretValue(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
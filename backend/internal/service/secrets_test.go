@@ -0,0 +1,114 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dcotelo/chartimpact/backend/internal/diff"
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	original := &models.StructuredDiffResult{
+		Resources: []models.ResourceDiff{
+			{
+				Identity: models.ResourceIdentity{APIVersion: "v1", Kind: "Secret", Name: "creds"},
+				Changes: []models.Change{
+					{Op: "replace", Path: "data.password", PathTokens: []interface{}{"data", "password"}, Before: "old-pass", After: "new-pass"},
+					{Op: "replace", Path: "stringData.cert", PathTokens: []interface{}{"stringData", "cert"}, Before: "a\nb", After: "a\nc", Hunks: []models.TextHunk{
+						{OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 2, Lines: []string{" a", "-b", "+c"}},
+					}},
+					{Op: "add", Path: "stringData", PathTokens: []interface{}{"stringData"}, After: map[string]interface{}{"token": "abc"}},
+					{Op: "replace", Path: "metadata.labels.app", PathTokens: []interface{}{"metadata", "labels", "app"}, Before: "a", After: "b"},
+				},
+			},
+			{
+				Identity: models.ResourceIdentity{APIVersion: "v1", Kind: "ConfigMap", Name: "config"},
+				Changes: []models.Change{
+					{Op: "replace", Path: "data.key", PathTokens: []interface{}{"data", "key"}, Before: "v1", After: "v2"},
+				},
+			},
+		},
+	}
+
+	redacted := RedactSecrets(original)
+	require.Len(t, redacted.Resources, 2)
+
+	secretChanges := redacted.Resources[0].Changes
+	assert.True(t, strings.HasPrefix(secretChanges[0].Before.(string), diff.RedactedPrefix))
+	assert.True(t, strings.HasPrefix(secretChanges[0].After.(string), diff.RedactedPrefix))
+	assert.True(t, strings.HasPrefix(secretChanges[1].After.(string), diff.RedactedPrefix))
+	assert.Nil(t, secretChanges[1].Hunks, "line diffs of secret values are dropped")
+	assert.True(t, strings.HasPrefix(secretChanges[2].After.(map[string]interface{})["token"].(string), diff.RedactedPrefix))
+	assert.Equal(t, "b", secretChanges[3].After, "non-data fields are left alone")
+
+	assert.Equal(t, "v2", redacted.Resources[1].Changes[0].After, "only Secrets are redacted")
+
+	// The original result is still being served to the client and must not change
+	assert.Equal(t, "new-pass", original.Resources[0].Changes[0].After)
+	assert.Len(t, original.Resources[0].Changes[1].Hunks, 1)
+	assert.Nil(t, RedactSecrets(nil))
+}
+
+func TestRedactSecrets_ValuesOverrides(t *testing.T) {
+	original := &models.StructuredDiffResult{
+		Values: &models.ValuesDiff{
+			Inherited: []models.DefaultValueChange{{Path: "replicas", Op: "replace", Before: 1, After: 2}},
+			Masked: []models.DefaultValueChange{
+				{Path: "auth.password", Op: "replace", Before: "", After: "changeme", Override: "s3cr3t"},
+				{Path: "auth.pin", Op: "replace", Before: 0, After: 1, Override: 4242},
+				{Path: "auth.tokens", Op: "add", After: "x", Override: map[string]interface{}{"api": "t0k3n", "ids": []interface{}{"id-one"}}},
+			},
+		},
+	}
+
+	redacted := RedactSecrets(original)
+	require.NotNil(t, redacted.Values)
+	require.Len(t, redacted.Values.Masked, 3)
+
+	masked := redacted.Values.Masked
+	assert.True(t, strings.HasPrefix(masked[0].Override.(string), diff.RedactedPrefix))
+	assert.True(t, strings.HasPrefix(masked[1].Override.(string), diff.RedactedPrefix))
+	tokens := masked[2].Override.(map[string]interface{})
+	assert.True(t, strings.HasPrefix(tokens["api"].(string), diff.RedactedPrefix))
+	assert.True(t, strings.HasPrefix(tokens["ids"].([]interface{})[0].(string), diff.RedactedPrefix))
+	assert.Equal(t, "changeme", masked[0].After, "chart defaults are left alone")
+	assert.Equal(t, original.Values.Inherited, redacted.Values.Inherited)
+
+	serialized, err := json.Marshal(redacted)
+	require.NoError(t, err)
+	for _, value := range []string{"s3cr3t", "t0k3n", "id-one"} {
+		assert.NotContains(t, string(serialized), value)
+	}
+
+	// The original result is still being served to the client and must not change
+	assert.Equal(t, "s3cr3t", original.Values.Masked[0].Override)
+}
+
+func TestRedactSecrets_Objects(t *testing.T) {
+	original := &models.StructuredDiffResult{
+		Resources: []models.ResourceDiff{
+			{
+				Identity:   models.ResourceIdentity{APIVersion: "v1", Kind: "Secret", Name: "creds"},
+				ChangeType: "added",
+				Object: map[string]interface{}{
+					"kind":       "Secret",
+					"data":       map[string]interface{}{"password": "bmV3LXBhc3M="},
+					"stringData": map[string]interface{}{"token": "abc"},
+				},
+			},
+		},
+	}
+
+	redacted := RedactSecrets(original)
+	obj := redacted.Resources[0].Object
+	assert.Equal(t, "Secret", obj["kind"])
+	assert.True(t, strings.HasPrefix(obj["data"].(map[string]interface{})["password"].(string), diff.RedactedPrefix))
+	assert.True(t, strings.HasPrefix(obj["stringData"].(map[string]interface{})["token"].(string), diff.RedactedPrefix))
+
+	assert.Equal(t, "bmV3LXBhc3M=", original.Resources[0].Object["data"].(map[string]interface{})["password"])
+}
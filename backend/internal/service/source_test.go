@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+func TestCompareVersions_SubchartSources(t *testing.T) {
+	umbrella := func(maxmemory string) map[string]string {
+		return map[string]string{
+			"chart/Chart.yaml":                            testChartYaml,
+			"chart/values.yaml":                           "replicas: 1\nlogLevel: info\nregion: us\n",
+			"chart/templates/configmap.yaml":              testConfigMapTemplate,
+			"chart/charts/redis/Chart.yaml":               "apiVersion: v2\nname: redis\nversion: 1.0.0\n",
+			"chart/charts/redis/values.yaml":              "maxmemory: " + maxmemory + "\n",
+			"chart/charts/redis/templates/configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: redis\ndata:\n  maxmemory: {{ .Values.maxmemory | quote }}\n",
+		}
+	}
+	repo := newTestChartRepo(t, []string{"v1", "v2"}, map[string]map[string]string{
+		"v1": umbrella("1gb"),
+		"v2": umbrella("2gb"),
+	})
+	service := newTestHelmService(t)
+
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "chart",
+		Version1:   "v1",
+		Version2:   "v2",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success, resp.Error)
+
+	require.Len(t, resp.StructuredDiff.Resources, 1)
+	assert.Equal(t, &models.ResourceSource{
+		Template: "demo/charts/redis/templates/configmap.yaml",
+		Chart:    "redis",
+		Subchart: "redis",
+	}, resp.StructuredDiff.Resources[0].Source)
+
+	require.Len(t, resp.StructuredDiff.Stats.BySubchart, 1)
+	assert.Equal(t, "redis", resp.StructuredDiff.Stats.BySubchart[0].Subchart)
+	assert.Equal(t, 1, resp.StructuredDiff.Stats.BySubchart[0].Resources.Modified)
+}
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/dcotelo/chartimpact/backend/internal/diff"
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+	"github.com/dcotelo/chartimpact/backend/internal/util"
+)
+
+const (
+	// SourceTypeGit fetches charts from a Git repository at a tag, branch or commit (default)
+	SourceTypeGit = "git"
+	// SourceTypeHelmRepo fetches packaged charts from a classic Helm repository (index.yaml)
+	SourceTypeHelmRepo = "helm-repo"
+	// SourceTypeOCI fetches packaged charts from an OCI registry
+	SourceTypeOCI = "oci"
+	// SourceTypeTarball reads packaged charts from a local directory under LOCAL_CHARTS_DIR
+	SourceTypeTarball = "tarball"
+)
+
+// ChartSource identifies where chart versions are fetched from
+type ChartSource struct {
+	Type       string // One of the SourceType constants
+	Repository string // Git URL, Helm repository URL, oci:// reference or local directory
+	Chart      string // Chart or kustomization path in a Git repository, or chart name for packaged sources
+}
+
+// FetchedChart is a chart version available on local disk
+type FetchedChart struct {
+	ChartDir   string // Unpacked chart directory
+	ValuesRoot string // Directory values file paths are resolved against
+	Packaged   bool   // Packaged charts ship their dependencies in charts/
+
+	// Kustomization marks ChartDir as a kustomization directory, built with kustomize instead of Helm
+	Kustomization bool
+}
+
+// ChartFetcher makes chart versions from one source available on local disk.
+// Values files are resolved against ValuesRoot right after each Fetch, since
+// fetching the next version may change its contents (e.g. a Git checkout).
+type ChartFetcher interface {
+	Fetch(ctx context.Context, version, destDir string) (*FetchedChart, error)
+}
+
+// FetcherFactory creates a fetcher for a chart source, using workDir for scratch files
+type FetcherFactory func(ctx context.Context, source ChartSource, workDir string) (ChartFetcher, error)
+
+// RegisterFetcher adds or replaces the fetcher used for a source type
+func (h *HelmService) RegisterFetcher(sourceType string, factory FetcherFactory) {
+	h.fetchers[sourceType] = factory
+}
+
+// IsValidSourceType reports whether sourceType is a supported chart source.
+// An empty type selects the default (git).
+func IsValidSourceType(sourceType string) bool {
+	switch sourceType {
+	case "", SourceTypeGit, SourceTypeHelmRepo, SourceTypeOCI, SourceTypeTarball, SourceTypeKustomize:
+		return true
+	default:
+		return false
+	}
+}
+
+// ResolveSources returns the effective source of each side of a comparison.
+// Per-side descriptors override the shared repository, chart path and source type;
+// values-only comparisons render the shared source at Version on both sides.
+func ResolveSources(req *models.CompareRequest) (left, right models.SourceDescriptor) {
+	shared := models.SourceDescriptor{
+		Repository: req.Repository,
+		ChartPath:  req.ChartPath,
+		SourceType: req.SourceType,
+	}
+	if shared.SourceType == "" {
+		shared.SourceType = SourceTypeGit
+	}
+
+	if req.Version != "" {
+		shared.Version = req.Version
+		return shared, shared
+	}
+
+	left, right = shared, shared
+	left.Version = req.Version1
+	right.Version = req.Version2
+	overrideSource(&left, req.Left)
+	overrideSource(&right, req.Right)
+	return left, right
+}
+
+// overrideSource applies the non-empty fields of a per-side descriptor
+func overrideSource(source *models.SourceDescriptor, override *models.SourceDescriptor) {
+	if override == nil {
+		return
+	}
+	if override.Repository != "" {
+		source.Repository = override.Repository
+	}
+	if override.ChartPath != "" {
+		source.ChartPath = override.ChartPath
+	}
+	if override.Version != "" {
+		source.Version = override.Version
+	}
+	if override.SourceType != "" {
+		source.SourceType = override.SourceType
+	}
+}
+
+// chartSource returns the chart source described by a source descriptor
+func chartSource(source models.SourceDescriptor) ChartSource {
+	return ChartSource{
+		Type:       source.SourceType,
+		Repository: source.Repository,
+		Chart:      source.ChartPath,
+	}
+}
+
+// openFetchers creates the fetchers for both sides. Sides sharing a source share
+// one fetcher, so a Git repository is cloned once; otherwise each side gets its
+// own scratch directory.
+func (h *HelmService) openFetchers(ctx context.Context, left, right models.SourceDescriptor, workDir string) (ChartFetcher, ChartFetcher, error) {
+	leftSource, rightSource := chartSource(left), chartSource(right)
+	if leftSource == rightSource {
+		fetcher, err := h.newFetcher(ctx, leftSource, workDir)
+		return fetcher, fetcher, err
+	}
+
+	leftDir, rightDir := filepath.Join(workDir, "source1"), filepath.Join(workDir, "source2")
+	for _, dir := range []string{leftDir, rightDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create source directory: %w", err)
+		}
+	}
+
+	leftFetcher, err := h.newFetcher(ctx, leftSource, leftDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("version 1: %w", err)
+	}
+	rightFetcher, err := h.newFetcher(ctx, rightSource, rightDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("version 2: %w", err)
+	}
+	return leftFetcher, rightFetcher, nil
+}
+
+// sourceMetadata describes one side of a comparison in the diff metadata
+func sourceMetadata(source models.SourceDescriptor) *diff.SourceMetadata {
+	return &diff.SourceMetadata{
+		Source:     source.SourceType,
+		Repository: source.Repository,
+		Chart:      source.ChartPath,
+		Version:    source.Version,
+	}
+}
+
+// newFetcher creates the fetcher registered for the source type
+func (h *HelmService) newFetcher(ctx context.Context, source ChartSource, workDir string) (ChartFetcher, error) {
+	if source.Type == "" {
+		source.Type = SourceTypeGit
+	}
+	factory, ok := h.fetchers[source.Type]
+	if !ok {
+		return nil, fmt.Errorf("unsupported source type %q", source.Type)
+	}
+	return factory(ctx, source, workDir)
+}
+
+// defaultFetchers returns the built-in fetchers for each source type
+func (h *HelmService) defaultFetchers() map[string]FetcherFactory {
+	return map[string]FetcherFactory{
+		SourceTypeGit:       h.newGitFetcher,
+		SourceTypeHelmRepo:  h.newHelmRepoFetcher,
+		SourceTypeOCI:       h.newOCIFetcher,
+		SourceTypeTarball:   h.newTarballFetcher,
+		SourceTypeKustomize: h.newKustomizeFetcher,
+	}
+}
+
+// gitFetcher checks out versions from a single clone of a Git repository
+type gitFetcher struct {
+	h         *HelmService
+	repoDir   string
+	chartPath string
+}
+
+// newGitFetcher clones the repository once for all versions
+func (h *HelmService) newGitFetcher(ctx context.Context, source ChartSource, workDir string) (ChartFetcher, error) {
+	repoDir := filepath.Join(workDir, "repo")
+	if err := h.cloneRepository(ctx, source.Repository, repoDir); err != nil {
+		return nil, fmt.Errorf("failed to clone repository: %w", err)
+	}
+	return &gitFetcher{h: h, repoDir: repoDir, chartPath: source.Chart}, nil
+}
+
+// Fetch checks out version and copies the chart; values files are read from the repository
+func (f *gitFetcher) Fetch(ctx context.Context, version, destDir string) (*FetchedChart, error) {
+	if err := f.h.extractVersion(ctx, f.repoDir, f.chartPath, version, destDir); err != nil {
+		return nil, err
+	}
+	return &FetchedChart{ChartDir: destDir, ValuesRoot: f.repoDir}, nil
+}
+
+// helmRepoFetcher downloads packaged charts listed in a Helm repository index
+type helmRepoFetcher struct {
+	h       *HelmService
+	repoURL string
+	chart   string
+	index   *repo.IndexFile
+}
+
+// newHelmRepoFetcher downloads the repository index once for all versions
+func (h *HelmService) newHelmRepoFetcher(ctx context.Context, source ChartSource, workDir string) (ChartFetcher, error) {
+	chartRepo, err := repo.NewChartRepository(&repo.Entry{Name: "chartimpact", URL: source.Repository}, getter.All(h.settings))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Helm repository %s: %w", source.Repository, err)
+	}
+	chartRepo.CachePath = workDir
+
+	indexPath, err := chartRepo.DownloadIndexFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to download index from %s: %w", source.Repository, err)
+	}
+	index, err := repo.LoadIndexFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index from %s: %w", source.Repository, err)
+	}
+
+	return &helmRepoFetcher{h: h, repoURL: source.Repository, chart: source.Chart, index: index}, nil
+}
+
+// Fetch downloads and unpacks the chart version listed in the index
+func (f *helmRepoFetcher) Fetch(ctx context.Context, version, destDir string) (*FetchedChart, error) {
+	log.Infof("Fetching chart %s version %s from %s", f.chart, version, f.repoURL)
+
+	chartVersion, err := f.index.Get(f.chart, version)
+	if err != nil {
+		return nil, fmt.Errorf("chart %s version %s not found in %s", f.chart, version, f.repoURL)
+	}
+	if len(chartVersion.URLs) == 0 {
+		return nil, fmt.Errorf("chart %s version %s has no download URL", f.chart, version)
+	}
+	chartURL, err := repo.ResolveReferenceURL(f.repoURL, chartVersion.URLs[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid download URL for chart %s version %s: %w", f.chart, version, err)
+	}
+
+	dl := f.h.chartDownloader(nil)
+	archive, err := f.h.downloadChart(dl, chartURL, version, destDir)
+	if err != nil {
+		return nil, err
+	}
+	return f.h.unpackChart(archive, destDir)
+}
+
+// ociFetcher pulls packaged charts from an OCI registry
+type ociFetcher struct {
+	h      *HelmService
+	ref    string
+	client *registry.Client
+}
+
+// newOCIFetcher creates a registry client shared by all versions.
+// The chart reference is the repository with the chart name appended, e.g. oci://ghcr.io/org/charts + app.
+func (h *HelmService) newOCIFetcher(ctx context.Context, source ChartSource, workDir string) (ChartFetcher, error) {
+	if !registry.IsOCI(source.Repository) {
+		return nil, fmt.Errorf("OCI repository must start with oci://")
+	}
+
+	opts := []registry.ClientOption{registry.ClientOptCredentialsFile(h.settings.RegistryConfig)}
+	if util.GetBoolEnv("HELM_REGISTRY_PLAIN_HTTP", false) {
+		opts = append(opts, registry.ClientOptPlainHTTP())
+	}
+	client, err := registry.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	ref := strings.TrimSuffix(source.Repository, "/")
+	if source.Chart != "" {
+		ref += "/" + strings.Trim(source.Chart, "/")
+	}
+	return &ociFetcher{h: h, ref: ref, client: client}, nil
+}
+
+// Fetch pulls and unpacks the chart tagged with version
+func (f *ociFetcher) Fetch(ctx context.Context, version, destDir string) (*FetchedChart, error) {
+	log.Infof("Pulling chart %s:%s", f.ref, version)
+
+	archive, err := f.h.downloadChart(f.h.chartDownloader(f.client), f.ref, version, destDir)
+	if err != nil {
+		return nil, err
+	}
+	return f.h.unpackChart(archive, destDir)
+}
+
+// tarballFetcher reads packaged charts from a local directory
+type tarballFetcher struct {
+	h     *HelmService
+	dir   string
+	chart string
+}
+
+// newTarballFetcher resolves the chart directory inside LOCAL_CHARTS_DIR.
+// Local sources are disabled unless LOCAL_CHARTS_DIR is set, so API callers
+// cannot read arbitrary files from the server.
+func (h *HelmService) newTarballFetcher(ctx context.Context, source ChartSource, workDir string) (ChartFetcher, error) {
+	root := os.Getenv("LOCAL_CHARTS_DIR")
+	if root == "" {
+		return nil, fmt.Errorf("local tarball sources are disabled; set LOCAL_CHARTS_DIR to enable them")
+	}
+
+	dir, err := repoFilePath(root, source.Repository)
+	if err != nil {
+		return nil, err
+	}
+	return &tarballFetcher{h: h, dir: dir, chart: source.Chart}, nil
+}
+
+// Fetch unpacks <chart>-<version>.tgz, or version itself if it names a .tgz file
+func (f *tarballFetcher) Fetch(ctx context.Context, version, destDir string) (*FetchedChart, error) {
+	name := fmt.Sprintf("%s-%s.tgz", f.chart, version)
+	if strings.HasSuffix(version, ".tgz") {
+		name = version
+	}
+
+	archive, err := repoFilePath(f.dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(archive); err != nil {
+		return nil, fmt.Errorf("chart archive %s not found", name)
+	}
+	return f.h.unpackChart(archive, destDir)
+}
+
+// chartDownloader creates a Helm chart downloader, with OCI support if a registry client is given
+func (h *HelmService) chartDownloader(client *registry.Client) *downloader.ChartDownloader {
+	dl := &downloader.ChartDownloader{
+		Out:              io.Discard,
+		Verify:           downloader.VerifyNever,
+		Getters:          getter.All(h.settings),
+		RepositoryConfig: h.settings.RepositoryConfig,
+		RepositoryCache:  h.settings.RepositoryCache,
+	}
+	if client != nil {
+		dl.RegistryClient = client
+		dl.Options = append(dl.Options,
+			getter.WithRegistryClient(client),
+			getter.WithPlainHTTP(util.GetBoolEnv("HELM_REGISTRY_PLAIN_HTTP", false)),
+		)
+	}
+	return dl
+}
+
+// downloadChart downloads a chart archive into a downloads directory next to destDir
+func (h *HelmService) downloadChart(dl *downloader.ChartDownloader, ref, version, destDir string) (string, error) {
+	downloadDir := destDir + "-download"
+	if err := os.MkdirAll(downloadDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	archive, _, err := dl.DownloadTo(ref, version, downloadDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to download chart %s version %s: %w", ref, version, err)
+	}
+	return archive, nil
+}
+
+// unpackChart expands a chart archive into destDir. Values file paths are
+// resolved against the unpacked chart, e.g. ci/production-values.yaml.
+func (h *HelmService) unpackChart(archive, destDir string) (*FetchedChart, error) {
+	chart, err := loader.Load(archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart archive: %w", err)
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create chart directory: %w", err)
+	}
+	if err := chartutil.ExpandFile(destDir, archive); err != nil {
+		return nil, fmt.Errorf("failed to unpack chart archive: %w", err)
+	}
+
+	chartDir := filepath.Join(destDir, chart.Name())
+	return &FetchedChart{ChartDir: chartDir, ValuesRoot: chartDir, Packaged: true}, nil
+}
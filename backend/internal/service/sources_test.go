@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+// packageTestChart packages the demo chart at version into outDir and returns the archive path
+func packageTestChart(t *testing.T, outDir, version, replicas string) string {
+	t.Helper()
+
+	chartDir := filepath.Join(t.TempDir(), "demo")
+	files := map[string]string{
+		"Chart.yaml":               strings.Replace(testChartYaml, "0.1.0", version, 1),
+		"values.yaml":              "replicas: " + replicas + "\nlogLevel: info\nregion: us\n",
+		"ci/prod-values.yaml":      "region: eu\n",
+		"templates/configmap.yaml": testConfigMapTemplate,
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(chartDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+	}
+
+	chart, err := loader.LoadDir(chartDir)
+	require.NoError(t, err)
+	archive, err := chartutil.Save(chart, outDir)
+	require.NoError(t, err)
+	return archive
+}
+
+// assertPackagedDiff checks the diff between demo 0.1.0 (replicas 1) and 0.2.0 (replicas 2)
+// rendered with ci/prod-values.yaml from inside each chart
+func assertPackagedDiff(t *testing.T, resp *models.CompareResponse) {
+	t.Helper()
+	require.True(t, resp.Success, resp.Error)
+	require.NotNil(t, resp.StructuredDiff)
+	require.Len(t, resp.StructuredDiff.Resources, 1)
+	require.Len(t, resp.StructuredDiff.Resources[0].Changes, 1)
+
+	change := findTestChange(resp.StructuredDiff, "data.replicas")
+	require.NotNil(t, change)
+	assert.Equal(t, "1", change.Before)
+	assert.Equal(t, "2", change.After)
+}
+
+func TestCompareVersions_HelmRepoSource(t *testing.T) {
+	chartsDir := t.TempDir()
+	packageTestChart(t, chartsDir, "0.1.0", "1")
+	packageTestChart(t, chartsDir, "0.2.0", "2")
+
+	server := httptest.NewServer(http.FileServer(http.Dir(chartsDir)))
+	defer server.Close()
+
+	index, err := repo.IndexDirectory(chartsDir, server.URL)
+	require.NoError(t, err)
+	require.NoError(t, index.WriteFile(filepath.Join(chartsDir, "index.yaml"), 0644))
+
+	service := newTestHelmService(t)
+	valuesFile := "ci/prod-values.yaml"
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: server.URL,
+		ChartPath:  "demo",
+		SourceType: SourceTypeHelmRepo,
+		Version1:   "0.1.0",
+		Version2:   "0.2.0",
+		ValuesFile: &valuesFile,
+	})
+	require.NoError(t, err)
+	assertPackagedDiff(t, resp)
+
+	missing, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: server.URL,
+		ChartPath:  "demo",
+		SourceType: SourceTypeHelmRepo,
+		Version1:   "0.1.0",
+		Version2:   "9.9.9",
+	})
+	require.NoError(t, err)
+	assert.False(t, missing.Success)
+	assert.Contains(t, missing.Error, "chart demo version 9.9.9 not found")
+}
+
+// newTestRegistry serves packaged charts over the read-only subset of the OCI
+// distribution API used by `helm pull`. Charts are keyed by repository name and tag.
+func newTestRegistry(t *testing.T, charts map[string]map[string]string) *httptest.Server {
+	t.Helper()
+
+	blobs := make(map[string][]byte)
+	manifests := make(map[string][]byte)
+	addBlob := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		blobs[digest] = data
+		return digest
+	}
+
+	for name, tags := range charts {
+		for tag, archive := range tags {
+			chartData, err := os.ReadFile(archive)
+			require.NoError(t, err)
+			config := []byte(fmt.Sprintf(`{"name":%q,"version":%q,"apiVersion":"v2"}`, filepath.Base(name), tag))
+
+			manifest, err := json.Marshal(map[string]interface{}{
+				"schemaVersion": 2,
+				"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+				"config": map[string]interface{}{
+					"mediaType": registry.ConfigMediaType,
+					"digest":    addBlob(config),
+					"size":      len(config),
+				},
+				"layers": []map[string]interface{}{{
+					"mediaType": registry.ChartLayerMediaType,
+					"digest":    addBlob(chartData),
+					"size":      len(chartData),
+				}},
+			})
+			require.NoError(t, err)
+			manifests[name+":"+tag] = manifest
+			manifests[name+"@"+addBlob(manifest)] = manifest
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v2/")
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case strings.Contains(path, "/manifests/"):
+			parts := strings.SplitN(path, "/manifests/", 2)
+			key := parts[0] + ":" + parts[1]
+			if strings.HasPrefix(parts[1], "sha256:") {
+				key = parts[0] + "@" + parts[1]
+			}
+			manifest, ok := manifests[key]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			sum := sha256.Sum256(manifest)
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			w.Header().Set("Docker-Content-Digest", "sha256:"+hex.EncodeToString(sum[:]))
+			w.Header().Set("Content-Length", fmt.Sprint(len(manifest)))
+			if r.Method != http.MethodHead {
+				w.Write(manifest)
+			}
+		case strings.Contains(path, "/blobs/"):
+			digest := path[strings.LastIndex(path, "/")+1:]
+			blob, ok := blobs[digest]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Docker-Content-Digest", digest)
+			w.Header().Set("Content-Length", fmt.Sprint(len(blob)))
+			if r.Method != http.MethodHead {
+				w.Write(blob)
+			}
+		case strings.HasSuffix(path, "/tags/list"):
+			name := strings.TrimSuffix(path, "/tags/list")
+			tags := []string{}
+			for tag := range charts[name] {
+				tags = append(tags, tag)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"name": name, "tags": tags})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestCompareVersions_OCISource(t *testing.T) {
+	chartsDir := t.TempDir()
+	server := newTestRegistry(t, map[string]map[string]string{
+		"charts/demo": {
+			"0.1.0": packageTestChart(t, chartsDir, "0.1.0", "1"),
+			"0.2.0": packageTestChart(t, chartsDir, "0.2.0", "2"),
+		},
+	})
+	defer server.Close()
+
+	t.Setenv("HELM_REGISTRY_PLAIN_HTTP", "true")
+	t.Setenv("HELM_REGISTRY_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	service := newTestHelmService(t)
+
+	valuesFile := "ci/prod-values.yaml"
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: "oci://" + strings.TrimPrefix(server.URL, "http://") + "/charts",
+		ChartPath:  "demo",
+		SourceType: SourceTypeOCI,
+		Version1:   "0.1.0",
+		Version2:   "0.2.0",
+		ValuesFile: &valuesFile,
+	})
+	require.NoError(t, err)
+	assertPackagedDiff(t, resp)
+}
+
+func TestCompareVersions_TarballSource(t *testing.T) {
+	root := t.TempDir()
+	chartsDir := filepath.Join(root, "team")
+	require.NoError(t, os.MkdirAll(chartsDir, 0755))
+	packageTestChart(t, chartsDir, "0.1.0", "1")
+	packageTestChart(t, chartsDir, "0.2.0", "2")
+
+	service := newTestHelmService(t)
+	valuesFile := "ci/prod-values.yaml"
+	req := &models.CompareRequest{
+		Repository: "team",
+		ChartPath:  "demo",
+		SourceType: SourceTypeTarball,
+		Version1:   "0.1.0",
+		Version2:   "demo-0.2.0.tgz",
+		ValuesFile: &valuesFile,
+	}
+
+	t.Setenv("LOCAL_CHARTS_DIR", "")
+	disabled, err := service.CompareVersions(context.Background(), req)
+	require.NoError(t, err)
+	assert.False(t, disabled.Success)
+	assert.Contains(t, disabled.Error, "LOCAL_CHARTS_DIR")
+
+	t.Setenv("LOCAL_CHARTS_DIR", root)
+	resp, err := service.CompareVersions(context.Background(), req)
+	require.NoError(t, err)
+	assertPackagedDiff(t, resp)
+}
+
+type stubFetcher struct {
+	charts map[string]string
+}
+
+func (f *stubFetcher) Fetch(ctx context.Context, version, destDir string) (*FetchedChart, error) {
+	archive, ok := f.charts[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown version %s", version)
+	}
+	return (&HelmService{}).unpackChart(archive, destDir)
+}
+
+func TestRegisterFetcher(t *testing.T) {
+	chartsDir := t.TempDir()
+	fetcher := &stubFetcher{charts: map[string]string{
+		"0.1.0": packageTestChart(t, chartsDir, "0.1.0", "1"),
+		"0.2.0": packageTestChart(t, chartsDir, "0.2.0", "2"),
+	}}
+
+	service := newTestHelmService(t)
+	service.RegisterFetcher("stub", func(ctx context.Context, source ChartSource, workDir string) (ChartFetcher, error) {
+		assert.Equal(t, "demo", source.Chart)
+		return fetcher, nil
+	})
+
+	valuesFile := "ci/prod-values.yaml"
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		ChartPath:  "demo",
+		SourceType: "stub",
+		Version1:   "0.1.0",
+		Version2:   "0.2.0",
+		ValuesFile: &valuesFile,
+	})
+	require.NoError(t, err)
+	assertPackagedDiff(t, resp)
+}
+
+func TestCompareVersions_CrossSource(t *testing.T) {
+	// Left: packaged 0.1.0 from a local directory, right: chart in Git at main
+	root := t.TempDir()
+	packageTestChart(t, root, "0.1.0", "1")
+	t.Setenv("LOCAL_CHARTS_DIR", root)
+
+	gitChart := map[string]string{
+		"charts/demo/Chart.yaml":               testChartYaml,
+		"charts/demo/values.yaml":              "replicas: 2\nlogLevel: info\nregion: us\n",
+		"charts/demo/templates/configmap.yaml": testConfigMapTemplate,
+	}
+	repo := newTestChartRepo(t, []string{"main"}, map[string]map[string]string{"main": gitChart})
+	service := newTestHelmService(t)
+
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "charts/demo",
+		Version2:   "main",
+		Left: &models.SourceDescriptor{
+			Repository: ".",
+			ChartPath:  "demo",
+			Version:    "0.1.0",
+			SourceType: SourceTypeTarball,
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success, resp.Error)
+	assert.Equal(t, "0.1.0", resp.Version1)
+	assert.Equal(t, "main", resp.Version2)
+
+	change := findTestChange(resp.StructuredDiff, "data.replicas")
+	require.NotNil(t, change)
+	assert.Equal(t, "1", change.Before)
+	assert.Equal(t, "2", change.After)
+
+	inputs := resp.StructuredDiff.Metadata.Inputs
+	assert.Equal(t, models.SourceMetadata{Source: SourceTypeTarball, Repository: ".", Chart: "demo", Version: "0.1.0"}, inputs.Left)
+	assert.Equal(t, models.SourceMetadata{Source: SourceTypeGit, Repository: repo, Chart: "charts/demo", Version: "main"}, inputs.Right)
+}
+
+func TestResolveSources(t *testing.T) {
+	req := &models.CompareRequest{
+		Repository: "https://github.com/test/repo.git",
+		ChartPath:  "charts/app",
+		Version1:   "v1",
+		Version2:   "v2",
+		Right:      &models.SourceDescriptor{Repository: "oci://ghcr.io/test/charts", ChartPath: "app", SourceType: SourceTypeOCI},
+	}
+
+	left, right := ResolveSources(req)
+	assert.Equal(t, models.SourceDescriptor{Repository: "https://github.com/test/repo.git", ChartPath: "charts/app", Version: "v1", SourceType: SourceTypeGit}, left)
+	assert.Equal(t, models.SourceDescriptor{Repository: "oci://ghcr.io/test/charts", ChartPath: "app", Version: "v2", SourceType: SourceTypeOCI}, right)
+
+	// Values-only comparisons use the shared source on both sides
+	req.Version = "v3"
+	left, right = ResolveSources(req)
+	assert.Equal(t, left, right)
+	assert.Equal(t, "v3", right.Version)
+	assert.Equal(t, SourceTypeGit, right.SourceType)
+}
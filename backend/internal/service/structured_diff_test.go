@@ -50,7 +50,7 @@ spec:
         image: api:v2.0.0
 `
 
-	diffResult, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, false)
+	diffResult, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, &models.CompareRequest{})
 	require.NoError(t, err)
 	require.NotNil(t, diffResult, "diffResult should not be nil when using internal diff engine")
 	require.NotEmpty(t, diffRaw)
@@ -140,7 +140,7 @@ metadata:
   name: config3
 `
 
-	diffResult, _, err := service.compareRendered(ctx, manifest1, manifest2, false)
+	diffResult, _, err := service.compareRendered(ctx, manifest1, manifest2, &models.CompareRequest{})
 	require.NoError(t, err)
 	require.NotNil(t, diffResult)
 
@@ -209,7 +209,7 @@ data:
   key: value2
 `
 
-		diffResult, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, false)
+		diffResult, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, &models.CompareRequest{})
 		require.NoError(t, err)
 		require.NotNil(t, diffResult)
 		require.NotEmpty(t, diffRaw)
@@ -248,7 +248,7 @@ data:
   key: value2
 `
 
-		diffResult, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, false)
+		diffResult, diffRaw, err := service.compareRendered(ctx, manifest1, manifest2, &models.CompareRequest{})
 		require.NoError(t, err)
 		require.NotEmpty(t, diffRaw)
 
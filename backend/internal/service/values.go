@@ -0,0 +1,235 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+// valuesSource is a single -f layer: either a file in the repository or inline content
+type valuesSource struct {
+	repoPath string
+	content  *string
+}
+
+// valuesPlan is the ordered set of values inputs for one side of a comparison,
+// expressed relative to the repository so it can be resolved at any ref
+type valuesPlan struct {
+	sources   []valuesSource
+	set       []string
+	setString []string
+	setFile   []string
+}
+
+// buildValuesPlan combines the shared request values with the side-specific overrides.
+// Layering order matches `helm template -f valuesFile -f valuesFiles... -f inline
+// -f side.valuesFiles... -f side.inline --set ... --set-string ... --set-file ...`.
+func buildValuesPlan(req *models.CompareRequest, side *models.ValuesOverrides) valuesPlan {
+	var plan valuesPlan
+
+	if req.ValuesFile != nil && *req.ValuesFile != "" {
+		plan.sources = append(plan.sources, valuesSource{repoPath: *req.ValuesFile})
+	}
+	for _, file := range req.ValuesFiles {
+		plan.sources = append(plan.sources, valuesSource{repoPath: file})
+	}
+	if req.ValuesContent != nil && *req.ValuesContent != "" {
+		plan.sources = append(plan.sources, valuesSource{content: req.ValuesContent})
+	}
+	plan.set = append(plan.set, req.Set...)
+	plan.setString = append(plan.setString, req.SetString...)
+	plan.setFile = append(plan.setFile, req.SetFile...)
+
+	if side != nil {
+		for _, file := range side.ValuesFiles {
+			plan.sources = append(plan.sources, valuesSource{repoPath: file})
+		}
+		if side.ValuesContent != nil && *side.ValuesContent != "" {
+			plan.sources = append(plan.sources, valuesSource{content: side.ValuesContent})
+		}
+		plan.set = append(plan.set, side.Set...)
+		plan.setString = append(plan.setString, side.SetString...)
+		plan.setFile = append(plan.setFile, side.SetFile...)
+	}
+
+	return plan
+}
+
+// resolveValues copies every repository file referenced by the plan into valuesDir
+// while the repository is checked out at version, and returns Helm values options
+// pointing at those copies. Files missing at this ref are reported as errors.
+func (h *HelmService) resolveValues(repoDir, version, valuesDir string, plan valuesPlan) (*values.Options, error) {
+	if err := os.MkdirAll(valuesDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create values directory: %w", err)
+	}
+
+	opts := &values.Options{
+		Values:       plan.set,
+		StringValues: plan.setString,
+	}
+
+	for i, source := range plan.sources {
+		var data []byte
+		if source.content != nil {
+			data = []byte(*source.content)
+		} else {
+			sourcePath, err := repoFilePath(repoDir, source.repoPath)
+			if err != nil {
+				return nil, err
+			}
+			if data, err = os.ReadFile(sourcePath); err != nil {
+				return nil, fmt.Errorf("values file %s not found at %s", source.repoPath, version)
+			}
+		}
+
+		// Number the copies so layer order is preserved and names never collide
+		destPath := filepath.Join(valuesDir, fmt.Sprintf("values-%02d.yaml", i))
+		if err := os.WriteFile(destPath, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write values file: %w", err)
+		}
+		opts.ValueFiles = append(opts.ValueFiles, destPath)
+	}
+
+	for i, entry := range plan.setFile {
+		key, repoPath, ok := strings.Cut(entry, "=")
+		if !ok || key == "" || repoPath == "" {
+			return nil, fmt.Errorf("invalid setFile entry %q, expected key=path", entry)
+		}
+		sourcePath, err := repoFilePath(repoDir, repoPath)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(sourcePath); err != nil {
+			return nil, fmt.Errorf("setFile %s not found at %s", repoPath, version)
+		}
+		destPath := filepath.Join(valuesDir, fmt.Sprintf("set-file-%02d", i))
+		if err := h.copyFile(sourcePath, destPath); err != nil {
+			return nil, fmt.Errorf("failed to copy setFile %s: %w", repoPath, err)
+		}
+		opts.FileValues = append(opts.FileValues, key+"="+destPath)
+	}
+
+	return opts, nil
+}
+
+// repoFilePath resolves a repository-relative path, refusing paths that escape the repository
+func repoFilePath(repoDir, repoPath string) (string, error) {
+	if filepath.IsAbs(repoPath) {
+		return "", fmt.Errorf("path %s must be relative to the repository", repoPath)
+	}
+
+	fullPath := filepath.Join(repoDir, repoPath)
+	if !isWithinDir(repoDir, fullPath) {
+		return "", fmt.Errorf("path %s is outside the repository", repoPath)
+	}
+
+	// Symlinks must not point outside the repository either
+	if resolved, err := filepath.EvalSymlinks(fullPath); err == nil {
+		root, err := filepath.EvalSymlinks(repoDir)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve repository path: %w", err)
+		}
+		if !isWithinDir(root, resolved) {
+			return "", fmt.Errorf("path %s is outside the repository", repoPath)
+		}
+	}
+
+	return fullPath, nil
+}
+
+// repoSymlinkTarget returns the regular file a symlink in a repository points to,
+// or an error if it is dangling, points to a directory or leaves the repository
+func repoSymlinkTarget(repoDir, link string) (string, error) {
+	name, err := filepath.Rel(repoDir, link)
+	if err != nil {
+		name = link
+	}
+
+	root, err := filepath.EvalSymlinks(repoDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve repository path: %w", err)
+	}
+	target, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve symlink %s: %w", name, err)
+	}
+	if !isWithinDir(root, target) {
+		return "", fmt.Errorf("symlink %s points outside the repository", name)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("symlink %s does not point to a file", name)
+	}
+	return target, nil
+}
+
+// isWithinDir reports whether path is dir or inside it; both must be clean
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// mergeValues merges values using Helm's own -f/--set semantics: later files
+// override earlier ones key by key, then --set, --set-string and --set-file are
+// applied. Chart defaults are coalesced underneath by the install action at render time.
+func (h *HelmService) mergeValues(opts *values.Options) (map[string]interface{}, error) {
+	if opts == nil {
+		return map[string]interface{}{}, nil
+	}
+	vals, err := opts.MergeValues(getter.All(h.settings))
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge values: %w", err)
+	}
+	return vals, nil
+}
+
+// computeValuesHash returns the SHA-256 of the user-supplied values in merge order:
+// values file contents, then --set, --set-string and --set-file entries.
+// For a single values file this is the SHA-256 of its content. Returns "" if there are none.
+func computeValuesHash(opts *values.Options) (string, error) {
+	if opts == nil || (len(opts.ValueFiles) == 0 && len(opts.Values) == 0 &&
+		len(opts.StringValues) == 0 && len(opts.FileValues) == 0) {
+		return "", nil
+	}
+
+	hash := sha256.New()
+	for i, path := range opts.ValueFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read values file: %w", err)
+		}
+		if i > 0 {
+			hash.Write([]byte{0})
+		}
+		hash.Write(data)
+	}
+	for _, value := range opts.Values {
+		hash.Write([]byte("\x00set:" + value))
+	}
+	for _, value := range opts.StringValues {
+		hash.Write([]byte("\x00setString:" + value))
+	}
+	for _, entry := range opts.FileValues {
+		// Hash the file content rather than its temporary location
+		key, path, _ := strings.Cut(entry, "=")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read setFile: %w", err)
+		}
+		hash.Write([]byte("\x00setFile:" + key + "="))
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli/values"
+
+	"github.com/dcotelo/chartimpact/backend/internal/diff"
+	"github.com/dcotelo/chartimpact/backend/internal/models"
+)
+
+const testChartYaml = `apiVersion: v2
+name: demo
+version: 0.1.0
+`
+
+const testConfigMapTemplate = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: demo
+data:
+  replicas: {{ .Values.replicas | quote }}
+  logLevel: {{ .Values.logLevel | quote }}
+  region: {{ .Values.region | quote }}
+`
+
+// newTestChartRepo creates a local Git repository with one commit per tag.
+// Each tag maps to the files (relative path -> content) present at that tag.
+func newTestChartRepo(t *testing.T, tags []string, files map[string]map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoDir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repoDir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	git("init", "-q")
+	for _, tag := range tags {
+		git("rm", "-rq", "--ignore-unmatch", ".")
+		for path, content := range files[tag] {
+			fullPath := filepath.Join(repoDir, path)
+			require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+			require.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+		}
+		git("add", "-A")
+		git("commit", "-q", "--allow-empty", "-m", tag)
+		git("tag", tag)
+	}
+
+	return repoDir
+}
+
+// newTestHelmService creates a HelmService working in a per-test temp directory
+func newTestHelmService(t *testing.T) *HelmService {
+	t.Helper()
+	t.Setenv("TEMP_DIR", t.TempDir())
+	return NewHelmService()
+}
+
+func findTestChange(result *models.StructuredDiffResult, path string) *models.Change {
+	for _, resource := range result.Resources {
+		for i := range resource.Changes {
+			if resource.Changes[i].Path == path {
+				return &resource.Changes[i]
+			}
+		}
+	}
+	return nil
+}
+
+func TestCompareVersions_ValuesFile(t *testing.T) {
+	chart := map[string]string{
+		"chart/Chart.yaml":               testChartYaml,
+		"chart/values.yaml":              "replicas: 1\nlogLevel: info\nregion: us\n",
+		"chart/templates/configmap.yaml": testConfigMapTemplate,
+	}
+	v1 := copyFiles(chart)
+	v1["env/prod.yaml"] = "replicas: 3\nregion: eu\n"
+	v2 := copyFiles(chart)
+	v2["env/prod.yaml"] = "replicas: 5\nregion: eu\n"
+
+	repo := newTestChartRepo(t, []string{"v1", "v2"}, map[string]map[string]string{"v1": v1, "v2": v2})
+	service := newTestHelmService(t)
+
+	valuesFile := "env/prod.yaml"
+	inline := "logLevel: debug\nregion: ap\n"
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository:    repo,
+		ChartPath:     "chart",
+		Version1:      "v1",
+		Version2:      "v2",
+		ValuesFile:    &valuesFile,
+		ValuesContent: &inline,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success, resp.Error)
+	require.NotNil(t, resp.StructuredDiff)
+
+	// The values file at each ref is applied, inline values override it
+	change := findTestChange(resp.StructuredDiff, "data.replicas")
+	require.NotNil(t, change)
+	assert.Equal(t, "3", change.Before)
+	assert.Equal(t, "5", change.After)
+	assert.Len(t, resp.StructuredDiff.Resources[0].Changes, 1, "inline logLevel/region apply to both sides")
+
+	left := resp.StructuredDiff.Metadata.Inputs.Left.ValuesHash
+	right := resp.StructuredDiff.Metadata.Inputs.Right.ValuesHash
+	assert.Len(t, left, 64)
+	assert.Len(t, right, 64)
+	assert.NotEqual(t, left, right)
+}
+
+func TestCompareVersions_ValuesFileMissingAtRef(t *testing.T) {
+	chart := map[string]string{
+		"chart/Chart.yaml":               testChartYaml,
+		"chart/values.yaml":              "replicas: 1\nlogLevel: info\nregion: us\n",
+		"chart/templates/configmap.yaml": testConfigMapTemplate,
+	}
+	v2 := copyFiles(chart)
+	v2["env/prod.yaml"] = "replicas: 5\n"
+
+	repo := newTestChartRepo(t, []string{"v1", "v2"}, map[string]map[string]string{"v1": chart, "v2": v2})
+	service := newTestHelmService(t)
+
+	valuesFile := "env/prod.yaml"
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository: repo,
+		ChartPath:  "chart",
+		Version1:   "v1",
+		Version2:   "v2",
+		ValuesFile: &valuesFile,
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Error, "values file env/prod.yaml not found at v1")
+}
+
+func TestComputeValuesHash(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "values.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("replicas: 3\n"), 0644))
+
+	hash, err := computeValuesHash(&values.Options{ValueFiles: []string{file}})
+	require.NoError(t, err)
+	// SHA-256 of "replicas: 3\n"
+	assert.Equal(t, "9cf3a5f89adc05f90e87b284d40f8e39e1b763d9f9df307327e6f127ed492175", hash)
+
+	empty, err := computeValuesHash(&values.Options{})
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	withSet, err := computeValuesHash(&values.Options{ValueFiles: []string{file}, Values: []string{"replicas=4"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, withSet)
+}
+
+func TestCompareVersions_LayeredValuesAndSet(t *testing.T) {
+	chart := map[string]string{
+		"chart/Chart.yaml":               testChartYaml,
+		"chart/values.yaml":              "replicas: 1\nlogLevel: info\nregion: us\n",
+		"chart/templates/configmap.yaml": testConfigMapTemplate,
+		"env/base.yaml":                  "replicas: 2\nlogLevel: warn\n",
+		"env/prod.yaml":                  "replicas: 3\n",
+		"env/region.txt":                 "eu",
+	}
+	repo := newTestChartRepo(t, []string{"v1", "v2"}, map[string]map[string]string{"v1": chart, "v2": copyFiles(chart)})
+	service := newTestHelmService(t)
+
+	// Same chart on both sides: every difference comes from the per-side values
+	rightContent := "logLevel: debug\n"
+	resp, err := service.CompareVersions(context.Background(), &models.CompareRequest{
+		Repository:  repo,
+		ChartPath:   "chart",
+		Version1:    "v1",
+		Version2:    "v2",
+		ValuesFiles: []string{"env/base.yaml"},
+		LeftValues: &models.ValuesOverrides{
+			SetFile: []string{"region=env/region.txt"},
+		},
+		RightValues: &models.ValuesOverrides{
+			ValuesFiles:   []string{"env/prod.yaml"},
+			ValuesContent: &rightContent,
+			Set:           []string{"replicas=7"},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success, resp.Error)
+	require.NotNil(t, resp.StructuredDiff)
+
+	// --set wins over every values file, like helm template -f ... --set
+	replicas := findTestChange(resp.StructuredDiff, "data.replicas")
+	require.NotNil(t, replicas)
+	assert.Equal(t, "2", replicas.Before)
+	assert.Equal(t, "7", replicas.After)
+
+	logLevel := findTestChange(resp.StructuredDiff, "data.logLevel")
+	require.NotNil(t, logLevel)
+	assert.Equal(t, "warn", logLevel.Before)
+	assert.Equal(t, "debug", logLevel.After)
+
+	region := findTestChange(resp.StructuredDiff, "data.region")
+	require.NotNil(t, region)
+	assert.Equal(t, "eu", region.Before)
+	assert.Equal(t, "us", region.After)
+
+	assert.NotEqual(t, resp.StructuredDiff.Metadata.Inputs.Left.ValuesHash, resp.StructuredDiff.Metadata.Inputs.Right.ValuesHash)
+}
+
+func TestCompareValues(t *testing.T) {
+	chart := map[string]string{
+		"chart/Chart.yaml":               testChartYaml,
+		"chart/values.yaml":              "replicas: 1\nlogLevel: info\nregion: us\n",
+		"chart/templates/configmap.yaml": testConfigMapTemplate,
+		"env/prod.yaml":                  "replicas: 3\nregion: eu\n",
+	}
+	repo := newTestChartRepo(t, []string{"v1"}, map[string]map[string]string{"v1": chart})
+	service := newTestHelmService(t)
+
+	resp, err := service.CompareValues(context.Background(), &models.CompareRequest{
+		Repository:  repo,
+		ChartPath:   "chart",
+		Version:     "v1",
+		RightValues: &models.ValuesOverrides{ValuesFiles: []string{"env/prod.yaml"}},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success, resp.Error)
+	require.NotNil(t, resp.StructuredDiff)
+
+	assert.Equal(t, "v1", resp.Version1)
+	assert.Equal(t, "v1", resp.Version2)
+
+	metadata := resp.StructuredDiff.Metadata
+	assert.Equal(t, diff.ComparisonModeValues, metadata.ComparisonMode)
+	assert.Equal(t, "v1", metadata.Inputs.Left.Version)
+	assert.Equal(t, "v1", metadata.Inputs.Right.Version)
+	assert.Empty(t, metadata.Inputs.Left.ValuesHash)
+	assert.Len(t, metadata.Inputs.Right.ValuesHash, 64)
+
+	require.Len(t, resp.StructuredDiff.Resources, 1)
+	assert.Len(t, resp.StructuredDiff.Resources[0].Changes, 2)
+	region := findTestChange(resp.StructuredDiff, "data.region")
+	require.NotNil(t, region)
+	assert.Equal(t, "us", region.Before)
+	assert.Equal(t, "eu", region.After)
+}
+
+func TestCompareValues_RejectsPerSideSources(t *testing.T) {
+	resp, err := newTestHelmService(t).CompareValues(context.Background(), &models.CompareRequest{
+		Repository:  "https://github.com/test/repo.git",
+		ChartPath:   "chart",
+		Version:     "v1",
+		Right:       &models.SourceDescriptor{Repository: "https://github.com/fork/repo.git"},
+		RightValues: &models.ValuesOverrides{Set: []string{"replicas=3"}},
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Error, "left and right sources are not supported for values comparisons")
+}
+
+func TestRepoFilePath_RejectsEscapes(t *testing.T) {
+	repoDir := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.yaml"), []byte("x: 1\n"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.yaml"), filepath.Join(repoDir, "link.yaml")))
+
+	for _, path := range []string{"/etc/passwd", "../secret.yaml", "env/../../secret.yaml", "link.yaml"} {
+		_, err := repoFilePath(repoDir, path)
+		assert.Error(t, err, path)
+	}
+
+	resolved, err := repoFilePath(repoDir, "env/prod.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(repoDir, "env", "prod.yaml"), resolved)
+}
+
+func copyFiles(files map[string]string) map[string]string {
+	copied := make(map[string]string, len(files))
+	for path, content := range files {
+		copied[path] = content
+	}
+	return copied
+}
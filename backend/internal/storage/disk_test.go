@@ -90,6 +90,25 @@ func TestDiskStore_SaveAndGetByHash(t *testing.T) {
 		assert.Equal(t, req.Version2, retrieved.Version2)
 	})
 
+	t.Run("preserves included objects", func(t *testing.T) {
+		store := createTestStore(t)
+		ctx := context.Background()
+
+		req := createTestSaveRequest()
+		req.StructuredDiff.Resources[0].ChangeType = "added"
+		req.StructuredDiff.Resources[0].Object = map[string]interface{}{
+			"kind": "Deployment",
+			"spec": map[string]interface{}{"replicas": float64(3)},
+		}
+		_, err := store.Save(ctx, req)
+		require.NoError(t, err)
+
+		retrieved, err := store.GetByHash(ctx, req.ContentHash)
+		require.NoError(t, err)
+		require.Len(t, retrieved.StructuredDiff.Resources, 1)
+		assert.Equal(t, req.StructuredDiff.Resources[0].Object, retrieved.StructuredDiff.Resources[0].Object)
+	})
+
 	t.Run("returns error for non-existent hash", func(t *testing.T) {
 		store := createTestStore(t)
 		ctx := context.Background()
@@ -4,6 +4,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"hash"
+	"sort"
+	"strings"
 
 	"github.com/dcotelo/chartimpact/backend/internal/models"
 )
@@ -23,6 +26,24 @@ func ComputeContentHash(req *models.CompareRequest) string {
 	h.Write([]byte(req.Version2))
 	h.Write([]byte{0})
 
+	// Git is the default source type, so it hashes the same as an empty type
+	if req.SourceType != "" && req.SourceType != "git" {
+		h.Write([]byte("sourceType:"))
+		h.Write([]byte(req.SourceType))
+		h.Write([]byte{0})
+	}
+
+	// Values-only comparisons render a single version
+	if req.Version != "" {
+		h.Write([]byte("version:"))
+		h.Write([]byte(req.Version))
+		h.Write([]byte{0})
+	}
+
+	// Per-side sources of cross-source comparisons
+	writeSourceDescriptor(h, "left", req.Left)
+	writeSourceDescriptor(h, "right", req.Right)
+
 	// Add optional values file
 	if req.ValuesFile != nil && *req.ValuesFile != "" {
 		h.Write([]byte("valuesFile:"))
@@ -38,13 +59,27 @@ func ComputeContentHash(req *models.CompareRequest) string {
 		h.Write([]byte{0})
 	}
 
+	// Layered values are order-sensitive, like repeated -f and --set flags
+	writeList(h, "valuesFiles", req.ValuesFiles)
+	writeList(h, "set", req.Set)
+	writeList(h, "setString", req.SetString)
+	writeList(h, "setFile", req.SetFile)
+	writeValuesOverrides(h, "left", req.LeftValues)
+	writeValuesOverrides(h, "right", req.RightValues)
+
 	// Add other configuration options that affect output
 	if req.IgnoreLabels {
 		h.Write([]byte("ignoreLabels:true"))
 		h.Write([]byte{0})
 	}
 
-	if req.SecretHandling != "" {
+	if req.IncludeObjects {
+		h.Write([]byte("includeObjects:true"))
+		h.Write([]byte{0})
+	}
+
+	// "suppress" is the default, so it hashes the same as an empty mode
+	if req.SecretHandling != "" && req.SecretHandling != "suppress" {
 		h.Write([]byte(fmt.Sprintf("secretHandling:%s", req.SecretHandling)))
 		h.Write([]byte{0})
 	}
@@ -54,9 +89,66 @@ func ComputeContentHash(req *models.CompareRequest) string {
 		h.Write([]byte{0})
 	}
 
+	// Suppressed kinds are order-insensitive
+	if len(req.SuppressKinds) > 0 {
+		kinds := make([]string, len(req.SuppressKinds))
+		for i, kind := range req.SuppressKinds {
+			kinds[i] = strings.ToLower(strings.TrimSpace(kind))
+		}
+		sort.Strings(kinds)
+		h.Write([]byte("suppressKinds:"))
+		h.Write([]byte(strings.Join(kinds, ",")))
+		h.Write([]byte{0})
+	}
+
+	if req.SuppressRegex != nil && *req.SuppressRegex != "" {
+		h.Write([]byte("suppressRegex:"))
+		h.Write([]byte(*req.SuppressRegex))
+		h.Write([]byte{0})
+	}
+
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// writeList writes a labelled, ordered list of strings to the hash
+func writeList(h hash.Hash, label string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	h.Write([]byte(label + ":"))
+	for _, item := range items {
+		h.Write([]byte(item))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte{0})
+}
+
+// writeValuesOverrides writes the per-side values overrides to the hash
+func writeValuesOverrides(h hash.Hash, side string, overrides *models.ValuesOverrides) {
+	if overrides == nil {
+		return
+	}
+	writeList(h, side+".valuesFiles", overrides.ValuesFiles)
+	if overrides.ValuesContent != nil && *overrides.ValuesContent != "" {
+		valueHash := sha256.Sum256([]byte(*overrides.ValuesContent))
+		h.Write([]byte(side + ".valuesContent:"))
+		h.Write(valueHash[:])
+		h.Write([]byte{0})
+	}
+	writeList(h, side+".set", overrides.Set)
+	writeList(h, side+".setString", overrides.SetString)
+	writeList(h, side+".setFile", overrides.SetFile)
+}
+
+// writeSourceDescriptor writes a per-side source descriptor to the hash
+func writeSourceDescriptor(h hash.Hash, side string, source *models.SourceDescriptor) {
+	if source == nil {
+		return
+	}
+	h.Write([]byte(fmt.Sprintf("%s.source:%s\x00%s\x00%s\x00%s", side, source.SourceType, source.Repository, source.ChartPath, source.Version)))
+	h.Write([]byte{0})
+}
+
 // ComputeValuesSHA256 computes SHA-256 hash of values content
 func ComputeValuesSHA256(valuesContent string) string {
 	if valuesContent == "" {
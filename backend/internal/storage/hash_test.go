@@ -225,6 +225,160 @@ func TestComputeContentHash_IgnoreLabelsOption(t *testing.T) {
 	}
 }
 
+func TestComputeContentHash_IncludeObjectsOption(t *testing.T) {
+	req := &models.CompareRequest{
+		Repository: "https://github.com/test/repo.git",
+		ChartPath:  "charts/app",
+		Version1:   "1.0.0",
+		Version2:   "1.1.0",
+	}
+	plain := ComputeContentHash(req)
+
+	req.IncludeObjects = true
+	if ComputeContentHash(req) == plain {
+		t.Error("Expected IncludeObjects to change the hash")
+	}
+}
+
+func TestComputeContentHash_SuppressOptions(t *testing.T) {
+	base := func() *models.CompareRequest {
+		return &models.CompareRequest{
+			Repository: "https://github.com/test/repo.git",
+			ChartPath:  "charts/app",
+			Version1:   "1.0.0",
+			Version2:   "1.1.0",
+		}
+	}
+
+	plain := ComputeContentHash(base())
+
+	withKinds := base()
+	withKinds.SuppressKinds = []string{"Secret", "CustomResourceDefinition"}
+	if ComputeContentHash(withKinds) == plain {
+		t.Error("Expected SuppressKinds to change the hash")
+	}
+
+	reordered := base()
+	reordered.SuppressKinds = []string{"customresourcedefinition", "Secret"}
+	if ComputeContentHash(reordered) != ComputeContentHash(withKinds) {
+		t.Error("Expected SuppressKinds order and case to not affect the hash")
+	}
+
+	withRegex := base()
+	withRegex.SuppressRegex = stringPtr("checksum/.*")
+	if ComputeContentHash(withRegex) == plain {
+		t.Error("Expected SuppressRegex to change the hash")
+	}
+}
+
+func TestComputeContentHash_LayeredValues(t *testing.T) {
+	base := func() *models.CompareRequest {
+		return &models.CompareRequest{
+			Repository: "https://github.com/test/repo.git",
+			ChartPath:  "charts/app",
+			Version1:   "1.0.0",
+			Version2:   "1.1.0",
+		}
+	}
+
+	plain := ComputeContentHash(base())
+
+	files := base()
+	files.ValuesFiles = []string{"a.yaml", "b.yaml"}
+	if ComputeContentHash(files) == plain {
+		t.Error("Expected ValuesFiles to change the hash")
+	}
+
+	reordered := base()
+	reordered.ValuesFiles = []string{"b.yaml", "a.yaml"}
+	if ComputeContentHash(reordered) == ComputeContentHash(files) {
+		t.Error("Expected ValuesFiles order to affect the hash")
+	}
+
+	set := base()
+	set.Set = []string{"replicas=3"}
+	setString := base()
+	setString.SetString = []string{"replicas=3"}
+	if ComputeContentHash(set) == plain || ComputeContentHash(set) == ComputeContentHash(setString) {
+		t.Error("Expected Set and SetString to hash differently")
+	}
+
+	valuesOnly := base()
+	valuesOnly.Version = "1.0.0"
+	if ComputeContentHash(valuesOnly) == plain {
+		t.Error("Expected Version to change the hash")
+	}
+
+	left := base()
+	left.LeftValues = &models.ValuesOverrides{Set: []string{"replicas=3"}}
+	right := base()
+	right.RightValues = &models.ValuesOverrides{Set: []string{"replicas=3"}}
+	if ComputeContentHash(left) == ComputeContentHash(right) {
+		t.Error("Expected per-side values to be distinguished by side")
+	}
+}
+
+func TestComputeContentHash_SourceType(t *testing.T) {
+	req := &models.CompareRequest{
+		Repository: "https://charts.example.com",
+		ChartPath:  "app",
+		Version1:   "1.0.0",
+		Version2:   "1.1.0",
+	}
+	plain := ComputeContentHash(req)
+
+	req.SourceType = "git"
+	if ComputeContentHash(req) != plain {
+		t.Error("Expected explicit git source type to hash like the default")
+	}
+
+	req.SourceType = "helm-repo"
+	if ComputeContentHash(req) == plain {
+		t.Error("Expected SourceType to change the hash")
+	}
+}
+
+func TestComputeContentHash_SourceDescriptors(t *testing.T) {
+	base := func() *models.CompareRequest {
+		return &models.CompareRequest{
+			Repository: "https://github.com/test/repo.git",
+			ChartPath:  "charts/app",
+			Version1:   "main",
+			Version2:   "main",
+		}
+	}
+
+	plain := ComputeContentHash(base())
+
+	packaged := base()
+	packaged.Left = &models.SourceDescriptor{
+		Repository: "https://charts.example.com",
+		ChartPath:  "app",
+		Version:    "4.2.0",
+		SourceType: "helm-repo",
+	}
+	if ComputeContentHash(packaged) == plain {
+		t.Error("Expected left source descriptor to change the hash")
+	}
+
+	swapped := base()
+	swapped.Right = packaged.Left
+	if ComputeContentHash(swapped) == ComputeContentHash(packaged) {
+		t.Error("Expected the side of a source descriptor to affect the hash")
+	}
+
+	otherVersion := base()
+	otherVersion.Left = &models.SourceDescriptor{
+		Repository: "https://charts.example.com",
+		ChartPath:  "app",
+		Version:    "4.3.0",
+		SourceType: "helm-repo",
+	}
+	if ComputeContentHash(otherVersion) == ComputeContentHash(packaged) {
+		t.Error("Expected source descriptor version to affect the hash")
+	}
+}
+
 // Helper function
 func stringPtr(s string) *string {
 	return &s
@@ -2,10 +2,13 @@ package storage
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/dcotelo/chartimpact/backend/internal/models"
 )
 
 // Mock tests - these validate the logic without requiring a real database
@@ -283,3 +286,34 @@ func TestChartPopularity_ChangeRate(t *testing.T) {
 // Note: Integration tests with real database are in integration_test.go
 // with build tag: // +build integration
 // Those tests use a real PostgreSQL instance to test the full storage flow
+
+func TestCompressJSON_PreservesObjects(t *testing.T) {
+	result := &models.StructuredDiffResult{
+		Resources: []models.ResourceDiff{
+			{
+				Identity:   models.ResourceIdentity{APIVersion: "v1", Kind: "ConfigMap", Name: "config"},
+				ChangeType: "removed",
+				Object: map[string]interface{}{
+					"kind": "ConfigMap",
+					"data": map[string]interface{}{"key": "value"},
+				},
+			},
+		},
+	}
+
+	compressed, _, err := compressJSON(result)
+	if err != nil {
+		t.Fatalf("compressJSON failed: %v", err)
+	}
+
+	var decompressed models.StructuredDiffResult
+	if err := decompressJSON(compressed, &decompressed); err != nil {
+		t.Fatalf("decompressJSON failed: %v", err)
+	}
+	if len(decompressed.Resources) != 1 {
+		t.Fatalf("Expected 1 resource, got %d", len(decompressed.Resources))
+	}
+	if !reflect.DeepEqual(decompressed.Resources[0].Object, result.Resources[0].Object) {
+		t.Errorf("Expected object %v, got %v", result.Resources[0].Object, decompressed.Resources[0].Object)
+	}
+}